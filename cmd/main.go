@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"maps"
 	"net/http"
 	"os"
 	"os/signal"
@@ -17,7 +19,10 @@ import (
 	"eyeOne/internal/exchange"
 	"eyeOne/internal/handler"
 	"eyeOne/internal/httpclient"
+	"eyeOne/internal/market"
 	"eyeOne/internal/service"
+	"eyeOne/internal/stream"
+	"eyeOne/models"
 	"eyeOne/pkg/logger"
 )
 
@@ -32,32 +37,108 @@ func main() {
 	cfg := config.LoadEnv()
 	router := gin.Default()
 
+	client := httpclient.New(logger)
+	venues := []exchange.ExchangeType{
+		exchange.Binance, exchange.KuCoin, exchange.Bitpin, exchange.Wallex,
+		exchange.Nobitex, exchange.OKX, exchange.Bybit, exchange.Kraken,
+	}
 	exchanges := make(map[exchange.ExchangeType]exchange.Exchange)
+	for _, exType := range venues {
+		ex, err := newExchange(exType, cfg, client, logger, nil)
+		if err != nil {
+			logger.Fatal("Failed to initialize exchange", zap.String("exchange", string(exType)), zap.Error(err))
+		}
+		exchanges[exType] = ex
+	}
 
-	//? need api_key
-	binance, err := exchange.NewBinanceExchange(cfg.BinanceAPIKey, cfg.BinanceSecretKey)
-	if err != nil {
-		logger.Fatal("Failed to initialize Binance", zap.Error(err))
+	var reference exchange.Exchange
+	if cfg.PaperReference != "" {
+		var ok bool
+		if reference, ok = exchanges[exchange.ExchangeType(cfg.PaperReference)]; !ok {
+			logger.Fatal("Unknown exchange in PAPER_REFERENCE", zap.String("exchange", cfg.PaperReference))
+		}
 	}
-	kucoin, err := exchange.NewKucoinExchange(cfg.KucoinAPIKey, cfg.KucoinSecretKey, cfg.KucoinPassphrase)
+	paper, err := newExchange(exchange.Paper, cfg, client, logger, reference)
 	if err != nil {
-		logger.Fatal("Failed to initialize KuCoin", zap.Error(err))
+		logger.Fatal("Failed to initialize paper exchange", zap.Error(err))
 	}
+	exchanges[exchange.Paper] = paper
 
-	client := httpclient.New(logger)
-	bitpin, err := exchange.NewBitpinExchange(client, logger, cfg)
-	if err != nil {
-		logger.Fatal("Failed to initialize bitpin", zap.Error(err))
+	// Market rules belong to the venue, so only the default instances feed
+	// the registry; named accounts share them.
+	markets := maps.Clone(exchanges)
+
+	configs := make(map[exchange.ExchangeType]*config.Config, len(exchanges))
+	for exType := range exchanges {
+		configs[exType] = cfg
+	}
+	for _, account := range cfg.Accounts {
+		exType := exchange.AccountType(exchange.ExchangeType(account.Exchange), account.Name)
+		if _, ok := exchanges[exType]; ok {
+			logger.Fatal("Duplicate account in ACCOUNTS", zap.String("account", string(exType)))
+		}
+		ex, err := newExchange(exType.Venue(), account.Config, client, logger, reference)
+		if err != nil {
+			logger.Fatal("Failed to initialize account", zap.String("account", string(exType)), zap.Error(err))
+		}
+		exchanges[exType] = ex
+		configs[exType] = account.Config
+		logger.Info("Initialized account", zap.String("account", string(exType)))
+	}
+
+	environments := make(map[exchange.ExchangeType]models.Environment, len(exchanges))
+	var publicOnly []exchange.ExchangeType
+	for exType, exCfg := range configs {
+		venue := string(exType.Venue())
+		environments[exType] = exCfg.Environment(venue)
+		if env := environments[exType]; !env.IsProduction() {
+			logger.Warn("Exchange is not using production", zap.String("exchange", string(exType)), zap.String("environment", string(env)))
+		}
+		if !exCfg.HasCredentials(venue) {
+			logger.Info("No API credentials, serving market data only", zap.String("exchange", string(exType)))
+			publicOnly = append(publicOnly, exType)
+		}
 	}
 
-	exchanges[exchange.Binance] = binance
-	exchanges[exchange.KuCoin] = kucoin
-	exchanges[exchange.Bitpin] = bitpin
+	filterMode, err := market.ParseFilterMode(cfg.OrderFilterMode)
+	if err != nil {
+		logger.Fatal("Invalid order filter mode", zap.Error(err))
+	}
+	registry := market.NewRegistry(markets, filterMode)
+
+	marketCtx, stopMarkets := context.WithCancel(context.Background())
+	defer stopMarkets()
+	go registry.Run(marketCtx, cfg.MarketRefreshInterval)
+
+	// Named accounts follow their venue's entry in ENABLED_EXCHANGES.
+	enabledVenues := make(map[exchange.ExchangeType]bool, len(cfg.EnabledExchanges))
+	for _, name := range cfg.EnabledExchanges {
+		exType := exchange.ExchangeType(name)
+		if _, ok := exchanges[exType]; !ok {
+			logger.Fatal("Unknown exchange in ENABLED_EXCHANGES", zap.String("exchange", name))
+		}
+		enabledVenues[exType] = true
+	}
+	var enabled []exchange.ExchangeType
+	for exType := range exchanges {
+		if enabledVenues[exType.Venue()] {
+			enabled = append(enabled, exType)
+		}
+	}
+	logger.Info("Enabled exchanges", zap.Strings("exchanges", cfg.EnabledExchanges))
+
+	// Streams are market data, so they follow each venue's default config.
+	streams := stream.NewManager(logger)
+	streams.Register(exchange.Binance, stream.NewBinanceFeed(cfg.BinanceEnv), exchanges[exchange.Binance])
+	streams.Register(exchange.KuCoin, stream.NewKucoinFeed(client, cfg.KucoinEnv), exchanges[exchange.KuCoin])
+	if cfg.BitpinEnv.IsProduction() || cfg.BitpinStreamURL != "" {
+		streams.Register(exchange.Bitpin, stream.NewBitpinFeed(cfg.BitpinStreamURL), exchanges[exchange.Bitpin])
+	}
 
-	tradingService := service.NewTradingService(exchanges)
+	tradingService := service.NewTradingService(exchanges, registry, streams, enabled, publicOnly, environments)
 	h := handler.NewHandler(tradingService)
 
-	api.SetupRouter(router, h)
+	api.SetupRouter(router, h, tradingService, cfg.AdminToken)
 
 	server := &http.Server{
 		Addr:           ":" + cfg.Port,
@@ -88,3 +169,29 @@ func main() {
 		logger.Info("Server exited gracefully")
 	}
 }
+
+// newExchange builds one adapter instance from cfg. Named accounts get
+// their own instance so tokens, nonces and caches are never shared.
+func newExchange(exType exchange.ExchangeType, cfg *config.Config, client *httpclient.Client, logger *zap.Logger, reference exchange.Exchange) (exchange.Exchange, error) {
+	switch exType {
+	case exchange.Binance:
+		return exchange.NewBinanceExchange(cfg.BinanceAPIKey, cfg.BinanceSecretKey, cfg.BinanceEnv)
+	case exchange.KuCoin:
+		return exchange.NewKucoinExchange(cfg.KucoinAPIKey, cfg.KucoinSecretKey, cfg.KucoinPassphrase, cfg.KucoinEnv)
+	case exchange.Bitpin:
+		return exchange.NewBitpinExchange(client, logger, cfg)
+	case exchange.Wallex:
+		return exchange.NewWallexExchange(client, logger, cfg)
+	case exchange.Nobitex:
+		return exchange.NewNobitexExchange(client, logger, cfg)
+	case exchange.OKX:
+		return exchange.NewOKXExchange(client, logger, cfg)
+	case exchange.Bybit:
+		return exchange.NewBybitExchange(client, logger, cfg)
+	case exchange.Kraken:
+		return exchange.NewKrakenExchange(client, logger, cfg)
+	case exchange.Paper:
+		return exchange.NewPaperExchange(reference, logger, cfg)
+	}
+	return nil, fmt.Errorf("unknown exchange %s", exType)
+}
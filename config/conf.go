@@ -1,25 +1,72 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
 
+	"eyeOne/models"
 	"eyeOne/pkg/logger"
 )
 
 type Config struct {
-	Port             string
-	BinanceAPIKey    string
-	BinanceSecretKey string
-	KucoinAPIKey     string
-	KucoinSecretKey  string
-	KucoinPassphrase string
-	BitpinAPIKey     string
-	BitpinSecretKey  string
+	Port                  string
+	MarketRefreshInterval time.Duration
+	OrderFilterMode       string
+	EnabledExchanges      []string
+	AdminToken            string
+	BinanceAPIKey         string
+	BinanceSecretKey      string
+	KucoinAPIKey          string
+	KucoinSecretKey       string
+	KucoinPassphrase      string
+	BitpinAPIKey          string
+	BitpinSecretKey       string
+	WallexAPIKey          string
+	NobitexToken          string
+	OKXAPIKey             string
+	OKXSecretKey          string
+	OKXPassphrase         string
+	BybitAPIKey           string
+	BybitSecretKey        string
+	KrakenAPIKey          string
+	KrakenSecretKey       string
+	BinanceEnv            models.Environment
+	KucoinEnv             models.Environment
+	BitpinEnv             models.Environment
+	BitpinBaseURL         string
+	BitpinStreamURL       string
+	WallexEnv             models.Environment
+	WallexBaseURL         string
+	NobitexEnv            models.Environment
+	NobitexBaseURL        string
+	OKXEnv                models.Environment
+	BybitEnv              models.Environment
+	KrakenEnv             models.Environment
+	PaperReference        string
+	PaperFixture          string
+	PaperBalances         []string
+	PaperMakerFee         string
+	PaperTakerFee         string
+	Accounts              []Account
 }
 
+// Account is an extra named key set on an exchange, e.g. binance:mm. Its
+// variables are the exchange's own with the account name inserted:
+// BINANCE_MM_API_KEY, BINANCE_MM_SECRET_KEY, BINANCE_MM_ENV.
+type Account struct {
+	Exchange string
+	Name     string
+	Config   *Config
+}
+
+var validAccountName = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
 func LoadEnv() *Config {
 	logger := logger.GetLogger()
 
@@ -28,19 +75,224 @@ func LoadEnv() *Config {
 	}
 
 	cfg := &Config{
-		Port:             getEnv("PORT", "8080"),
-		BinanceAPIKey:    mustGetEnv("BINANCE_API_KEY", logger),
-		BinanceSecretKey: mustGetEnv("BINANCE_SECRET_KEY", logger),
-		KucoinAPIKey:     mustGetEnv("KUCOIN_API_KEY", logger),
-		KucoinSecretKey:  mustGetEnv("KUCOIN_SECRET_KEY", logger),
-		KucoinPassphrase: mustGetEnv("KUCOIN_PASSPHRASE", logger),
-		BitpinAPIKey:     mustGetEnv("BITPIN_API_KEY", logger),
-		BitpinSecretKey:  mustGetEnv("BITPIN_SECRET_KEY", logger),
+		Port:                  getEnv("PORT", "8080"),
+		MarketRefreshInterval: getDurationEnv("MARKET_REFRESH_INTERVAL", time.Hour, logger),
+		OrderFilterMode:       getEnv("ORDER_FILTER_MODE", "round"),
+		EnabledExchanges:      getListEnv("ENABLED_EXCHANGES", []string{"binance", "kucoin", "bitpin", "wallex", "nobitex", "okx", "bybit", "kraken", "paper"}),
+		AdminToken:            getEnv("ADMIN_TOKEN", ""),
+		BinanceAPIKey:         getEnv("BINANCE_API_KEY", ""),
+		BinanceSecretKey:      getEnv("BINANCE_SECRET_KEY", ""),
+		KucoinAPIKey:          getEnv("KUCOIN_API_KEY", ""),
+		KucoinSecretKey:       getEnv("KUCOIN_SECRET_KEY", ""),
+		KucoinPassphrase:      getEnv("KUCOIN_PASSPHRASE", ""),
+		BitpinAPIKey:          getEnv("BITPIN_API_KEY", ""),
+		BitpinSecretKey:       getEnv("BITPIN_SECRET_KEY", ""),
+		WallexAPIKey:          getEnv("WALLEX_API_KEY", ""),
+		NobitexToken:          getEnv("NOBITEX_TOKEN", ""),
+		OKXAPIKey:             getEnv("OKX_API_KEY", ""),
+		OKXSecretKey:          getEnv("OKX_SECRET_KEY", ""),
+		OKXPassphrase:         getEnv("OKX_PASSPHRASE", ""),
+		BybitAPIKey:           getEnv("BYBIT_API_KEY", ""),
+		BybitSecretKey:        getEnv("BYBIT_SECRET_KEY", ""),
+		KrakenAPIKey:          getEnv("KRAKEN_API_KEY", ""),
+		KrakenSecretKey:       getEnv("KRAKEN_SECRET_KEY", ""),
+		BinanceEnv:            getEnvironmentEnv("BINANCE_ENV", logger),
+		KucoinEnv:             getEnvironmentEnv("KUCOIN_ENV", logger),
+		BitpinEnv:             getEnvironmentEnv("BITPIN_ENV", logger),
+		BitpinBaseURL:         getEnv("BITPIN_BASE_URL", ""),
+		BitpinStreamURL:       getEnv("BITPIN_STREAM_URL", ""),
+		WallexEnv:             getEnvironmentEnv("WALLEX_ENV", logger),
+		WallexBaseURL:         getEnv("WALLEX_BASE_URL", ""),
+		NobitexEnv:            getEnvironmentEnv("NOBITEX_ENV", logger),
+		NobitexBaseURL:        getEnv("NOBITEX_BASE_URL", ""),
+		OKXEnv:                getEnvironmentEnv("OKX_ENV", logger),
+		BybitEnv:              getEnvironmentEnv("BYBIT_ENV", logger),
+		KrakenEnv:             getEnvironmentEnv("KRAKEN_ENV", logger),
+		PaperReference:        getEnv("PAPER_REFERENCE", "binance"),
+		PaperFixture:          getEnv("PAPER_FIXTURE", ""),
+		PaperBalances:         getListEnv("PAPER_BALANCES", []string{"usdt:10000"}),
+		PaperMakerFee:         getEnv("PAPER_MAKER_FEE", "0.001"),
+		PaperTakerFee:         getEnv("PAPER_TAKER_FEE", "0.001"),
+	}
+
+	checkCredentials("binance", logger, cfg.BinanceAPIKey, cfg.BinanceSecretKey)
+	checkCredentials("kucoin", logger, cfg.KucoinAPIKey, cfg.KucoinSecretKey, cfg.KucoinPassphrase)
+	checkCredentials("bitpin", logger, cfg.BitpinAPIKey, cfg.BitpinSecretKey)
+	checkCredentials("okx", logger, cfg.OKXAPIKey, cfg.OKXSecretKey, cfg.OKXPassphrase)
+	checkCredentials("bybit", logger, cfg.BybitAPIKey, cfg.BybitSecretKey)
+	checkCredentials("kraken", logger, cfg.KrakenAPIKey, cfg.KrakenSecretKey)
+
+	for _, item := range getListEnv("ACCOUNTS", nil) {
+		exchange, name, ok := strings.Cut(item, ":")
+		if !ok || !validAccountName.MatchString(name) {
+			logger.Fatal("Invalid account in ACCOUNTS, expected exchange:name", zap.String("account", item))
+		}
+		account, err := cfg.accountConfig(exchange, name, logger)
+		if err != nil {
+			logger.Fatal("Invalid account in ACCOUNTS", zap.String("account", item), zap.Error(err))
+		}
+		cfg.Accounts = append(cfg.Accounts, Account{Exchange: exchange, Name: name, Config: account})
 	}
 
 	return cfg
 }
 
+// accountConfig copies c with one exchange's credentials and environment
+// read from the account's variables instead.
+func (c *Config) accountConfig(exchange, name string, logger *zap.Logger) (*Config, error) {
+	prefix := strings.ToUpper(exchange + "_" + name + "_")
+	label := exchange + ":" + name
+	account := *c
+	account.Accounts = nil
+
+	switch exchange {
+	case "binance":
+		account.BinanceAPIKey = getEnv(prefix+"API_KEY", "")
+		account.BinanceSecretKey = getEnv(prefix+"SECRET_KEY", "")
+		account.BinanceEnv = getEnvironmentEnv(prefix+"ENV", logger)
+		checkCredentials(label, logger, account.BinanceAPIKey, account.BinanceSecretKey)
+	case "kucoin":
+		account.KucoinAPIKey = getEnv(prefix+"API_KEY", "")
+		account.KucoinSecretKey = getEnv(prefix+"SECRET_KEY", "")
+		account.KucoinPassphrase = getEnv(prefix+"PASSPHRASE", "")
+		account.KucoinEnv = getEnvironmentEnv(prefix+"ENV", logger)
+		checkCredentials(label, logger, account.KucoinAPIKey, account.KucoinSecretKey, account.KucoinPassphrase)
+	case "bitpin":
+		account.BitpinAPIKey = getEnv(prefix+"API_KEY", "")
+		account.BitpinSecretKey = getEnv(prefix+"SECRET_KEY", "")
+		account.BitpinEnv = getEnvironmentEnv(prefix+"ENV", logger)
+		account.BitpinBaseURL = getEnv(prefix+"BASE_URL", "")
+		checkCredentials(label, logger, account.BitpinAPIKey, account.BitpinSecretKey)
+	case "wallex":
+		account.WallexAPIKey = getEnv(prefix+"API_KEY", "")
+		account.WallexEnv = getEnvironmentEnv(prefix+"ENV", logger)
+		account.WallexBaseURL = getEnv(prefix+"BASE_URL", "")
+	case "nobitex":
+		account.NobitexToken = getEnv(prefix+"TOKEN", "")
+		account.NobitexEnv = getEnvironmentEnv(prefix+"ENV", logger)
+		account.NobitexBaseURL = getEnv(prefix+"BASE_URL", "")
+	case "okx":
+		account.OKXAPIKey = getEnv(prefix+"API_KEY", "")
+		account.OKXSecretKey = getEnv(prefix+"SECRET_KEY", "")
+		account.OKXPassphrase = getEnv(prefix+"PASSPHRASE", "")
+		account.OKXEnv = getEnvironmentEnv(prefix+"ENV", logger)
+		checkCredentials(label, logger, account.OKXAPIKey, account.OKXSecretKey, account.OKXPassphrase)
+	case "bybit":
+		account.BybitAPIKey = getEnv(prefix+"API_KEY", "")
+		account.BybitSecretKey = getEnv(prefix+"SECRET_KEY", "")
+		account.BybitEnv = getEnvironmentEnv(prefix+"ENV", logger)
+		checkCredentials(label, logger, account.BybitAPIKey, account.BybitSecretKey)
+	case "kraken":
+		account.KrakenAPIKey = getEnv(prefix+"API_KEY", "")
+		account.KrakenSecretKey = getEnv(prefix+"SECRET_KEY", "")
+		account.KrakenEnv = getEnvironmentEnv(prefix+"ENV", logger)
+		checkCredentials(label, logger, account.KrakenAPIKey, account.KrakenSecretKey)
+	case "paper":
+		account.PaperBalances = getListEnv(prefix+"BALANCES", c.PaperBalances)
+	default:
+		return nil, fmt.Errorf("unknown exchange %q", exchange)
+	}
+	return &account, nil
+}
+
+// HasCredentials reports whether the named exchange has a full key set.
+// The paper exchange needs none.
+func (c *Config) HasCredentials(exchange string) bool {
+	switch exchange {
+	case "binance":
+		return c.HasBinanceCredentials()
+	case "kucoin":
+		return c.HasKucoinCredentials()
+	case "bitpin":
+		return c.HasBitpinCredentials()
+	case "wallex":
+		return c.HasWallexCredentials()
+	case "nobitex":
+		return c.HasNobitexCredentials()
+	case "okx":
+		return c.HasOKXCredentials()
+	case "bybit":
+		return c.HasBybitCredentials()
+	case "kraken":
+		return c.HasKrakenCredentials()
+	case "paper":
+		return true
+	}
+	return false
+}
+
+// Environment returns the deployment the named exchange is configured
+// for. Paper orders never reach a venue, so paper is always a sandbox.
+func (c *Config) Environment(exchange string) models.Environment {
+	switch exchange {
+	case "binance":
+		return c.BinanceEnv
+	case "kucoin":
+		return c.KucoinEnv
+	case "bitpin":
+		return c.BitpinEnv
+	case "wallex":
+		return c.WallexEnv
+	case "nobitex":
+		return c.NobitexEnv
+	case "okx":
+		return c.OKXEnv
+	case "bybit":
+		return c.BybitEnv
+	case "kraken":
+		return c.KrakenEnv
+	case "paper":
+		return models.EnvironmentSandbox
+	}
+	return models.EnvironmentProduction
+}
+
+func (c *Config) HasBinanceCredentials() bool {
+	return c.BinanceAPIKey != "" && c.BinanceSecretKey != ""
+}
+
+func (c *Config) HasKucoinCredentials() bool {
+	return c.KucoinAPIKey != "" && c.KucoinSecretKey != "" && c.KucoinPassphrase != ""
+}
+
+func (c *Config) HasBitpinCredentials() bool {
+	return c.BitpinAPIKey != "" && c.BitpinSecretKey != ""
+}
+
+func (c *Config) HasWallexCredentials() bool {
+	return c.WallexAPIKey != ""
+}
+
+func (c *Config) HasNobitexCredentials() bool {
+	return c.NobitexToken != ""
+}
+
+func (c *Config) HasOKXCredentials() bool {
+	return c.OKXAPIKey != "" && c.OKXSecretKey != "" && c.OKXPassphrase != ""
+}
+
+func (c *Config) HasBybitCredentials() bool {
+	return c.BybitAPIKey != "" && c.BybitSecretKey != ""
+}
+
+func (c *Config) HasKrakenCredentials() bool {
+	return c.KrakenAPIKey != "" && c.KrakenSecretKey != ""
+}
+
+// checkCredentials refuses to start with a partially configured key set,
+// which is almost always a typo rather than an intent to run public-only.
+func checkCredentials(exchange string, logger *zap.Logger, values ...string) {
+	set := 0
+	for _, v := range values {
+		if v != "" {
+			set++
+		}
+	}
+	if set > 0 && set < len(values) {
+		logger.Fatal("Incomplete API credentials", zap.String("exchange", exchange))
+	}
+}
+
 func getEnv(key, defaultVal string) string {
 	if val := os.Getenv(key); val != "" {
 		return val
@@ -48,10 +300,36 @@ func getEnv(key, defaultVal string) string {
 	return defaultVal
 }
 
-func mustGetEnv(key string, logger *zap.Logger) string {
+func getListEnv(key string, defaultVal []string) []string {
 	val := os.Getenv(key)
 	if val == "" {
-		logger.Fatal("Required environment variable not set", zap.String("key", key))
+		return defaultVal
+	}
+	var list []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func getDurationEnv(key string, defaultVal time.Duration, logger *zap.Logger) time.Duration {
+	val := os.Getenv(key)
+	if val == "" {
+		return defaultVal
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil || d <= 0 {
+		logger.Fatal("Invalid duration in environment variable", zap.String("key", key), zap.String("value", val))
+	}
+	return d
+}
+
+func getEnvironmentEnv(key string, logger *zap.Logger) models.Environment {
+	env, err := models.ParseEnvironment(os.Getenv(key))
+	if err != nil {
+		logger.Fatal("Invalid environment in environment variable", zap.String("key", key), zap.Error(err))
 	}
-	return val
+	return env
 }
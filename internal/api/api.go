@@ -5,12 +5,38 @@ import (
 
 	"eyeOne/internal/handler"
 	"eyeOne/internal/middleware"
+	"eyeOne/internal/service"
 )
 
-func SetupRouter(router *gin.Engine, h *handler.Handler) {
+func SetupRouter(router *gin.Engine, h *handler.Handler, ts *service.TradingService, adminToken string) {
+	exchangeMiddleware := middleware.ExchangeMiddleware(ts)
+
 	api := router.Group("/api/v1")
-	api.POST("/order/:exchange", middleware.ExchangeMiddleware(), h.CreateOrder)
-	api.DELETE("/order/:exchange/:orderID", middleware.ExchangeMiddleware(), h.CancelOrder)
-	api.GET("/balance/:exchange/:asset", middleware.ExchangeMiddleware(), h.GetBalance)
-	api.GET("/order-book/:exchange/:symbol", middleware.ExchangeMiddleware(), h.GetOrderBook)
+	api.GET("/exchanges", h.ListExchangeStatus)
+	api.POST("/order/:exchange", exchangeMiddleware, h.CreateOrder)
+	api.POST("/order/:exchange/:account", exchangeMiddleware, h.CreateOrder)
+	api.GET("/order/:exchange/:orderID", exchangeMiddleware, h.GetOrder)
+	api.DELETE("/order/:exchange/:orderID", exchangeMiddleware, h.CancelOrder)
+	api.GET("/orders/:exchange", exchangeMiddleware, h.ListOpenOrders)
+	api.GET("/orders/:exchange/history", exchangeMiddleware, h.ListOrderHistory)
+	api.DELETE("/orders/:exchange", exchangeMiddleware, h.CancelAllOrders)
+	api.DELETE("/orders", h.CancelAllOrdersEverywhere)
+	api.GET("/fills/:exchange", exchangeMiddleware, h.GetFills)
+	api.GET("/markets/:exchange", exchangeMiddleware, h.GetMarkets)
+	api.GET("/balance/:exchange/:asset", exchangeMiddleware, h.GetBalance)
+	api.GET("/balances/:exchange", exchangeMiddleware, h.GetBalances)
+	api.GET("/balances", h.GetAllBalances)
+	api.GET("/order-book/:exchange/:symbol", exchangeMiddleware, h.GetOrderBook)
+	api.GET("/ticker/:exchange/:symbol", exchangeMiddleware, h.GetTicker)
+	api.GET("/tickers/:exchange", exchangeMiddleware, h.GetTickers)
+	api.GET("/candles/:exchange/:symbol", exchangeMiddleware, h.GetCandles)
+	api.GET("/trades/:exchange/:symbol", exchangeMiddleware, h.GetRecentTrades)
+
+	ws := router.Group("/ws/v1")
+	ws.GET("/orderbook/:exchange/:symbol", exchangeMiddleware, h.StreamOrderBook)
+
+	admin := api.Group("/admin", middleware.AdminMiddleware(adminToken))
+	admin.GET("/exchanges", h.ListExchangeStatus)
+	admin.PUT("/exchanges/:exchange", h.SetExchangeEnabled)
+	admin.POST("/exchanges/:exchange/reset", h.ResetExchange)
 }
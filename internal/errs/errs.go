@@ -0,0 +1,96 @@
+// Package errs defines the error kinds that exchange adapters report, so
+// callers can decide how to react to a failure (retry, fix the request,
+// top up funds) without parsing venue-specific error messages.
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Kind is a category of failure. Kinds are comparable sentinels and are
+// matched with errors.Is.
+type Kind struct {
+	code    string
+	message string
+}
+
+func (k *Kind) Error() string { return k.message }
+
+// Code returns the machine-readable identifier of the kind.
+func (k *Kind) Code() string { return k.code }
+
+var (
+	ErrInvalidRequest      = &Kind{code: "INVALID_REQUEST", message: "invalid request"}
+	ErrInsufficientFunds   = &Kind{code: "INSUFFICIENT_FUNDS", message: "insufficient funds"}
+	ErrSymbolNotFound      = &Kind{code: "SYMBOL_NOT_FOUND", message: "symbol not found"}
+	ErrAssetNotFound       = &Kind{code: "ASSET_NOT_FOUND", message: "asset not found"}
+	ErrOrderNotFound       = &Kind{code: "ORDER_NOT_FOUND", message: "order not found"}
+	ErrRateLimited         = &Kind{code: "RATE_LIMITED", message: "rate limited by exchange"}
+	ErrAuthFailed          = &Kind{code: "AUTH_FAILED", message: "exchange authentication failed"}
+	ErrCredentialsRequired = &Kind{code: "CREDENTIALS_REQUIRED", message: "exchange credentials not configured"}
+	ErrExchangeUnavailable = &Kind{code: "EXCHANGE_UNAVAILABLE", message: "exchange unavailable"}
+	ErrExchangeNotFound    = &Kind{code: "EXCHANGE_NOT_FOUND", message: "exchange not found"}
+	ErrNotSupported        = &Kind{code: "NOT_SUPPORTED", message: "operation not supported by exchange"}
+	ErrInternal            = &Kind{code: "INTERNAL", message: "internal error"}
+)
+
+// Error attaches a Kind to an underlying error while keeping its message.
+type Error struct {
+	Kind *Kind
+	Err  error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+
+func (e *Error) Unwrap() []error { return []error{e.Kind, e.Err} }
+
+// Wrap classifies err as kind. It returns nil when err is nil and leaves
+// errors that already carry a kind untouched.
+func Wrap(kind *Kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	var existing *Kind
+	if errors.As(err, &existing) {
+		return err
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// New builds a classified error from a format string.
+func New(kind *Kind, format string, args ...any) error {
+	return &Error{Kind: kind, Err: fmt.Errorf(format, args...)}
+}
+
+// KindOf returns the kind carried by err, or ErrInternal when it has none.
+func KindOf(err error) *Kind {
+	var kind *Kind
+	if errors.As(err, &kind) {
+		return kind
+	}
+	return ErrInternal
+}
+
+// Code returns the machine-readable code of the kind carried by err.
+func Code(err error) string {
+	return KindOf(err).Code()
+}
+
+// FromHTTPStatus maps an upstream HTTP status to a kind. notFound is used
+// for 404 responses since only the caller knows what was being looked up.
+func FromHTTPStatus(status int, notFound *Kind) *Kind {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return ErrAuthFailed
+	case status == http.StatusNotFound:
+		return notFound
+	case status == http.StatusTooManyRequests || status == http.StatusTeapot:
+		return ErrRateLimited
+	case status >= 400 && status < 500:
+		return ErrInvalidRequest
+	default:
+		return ErrExchangeUnavailable
+	}
+}
@@ -2,128 +2,543 @@ package exchange
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/common"
+	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 
+	"eyeOne/internal/errs"
 	"eyeOne/models"
 	"eyeOne/pkg/logger"
 )
 
+const (
+	binanceMaxOrderLimit = 1000
+	binanceMaxTradeLimit = 1000
+	binanceMaxTradePages = 10
+	binanceMaxCandles    = 1000
+	binanceMaxTrades     = 1000
+)
+
 type BinanceExchange struct {
-	client *binance.Client
-	log    *zap.Logger
+	client  *binance.Client
+	symbols *symbolIndex
+	log     *zap.Logger
 }
 
-func NewBinanceExchange(apiKey, secretKey string) (Exchange, error) {
+func NewBinanceExchange(apiKey, secretKey string, env models.Environment) (Exchange, error) {
 	client := binance.NewClient(apiKey, secretKey)
+	if !env.IsProduction() {
+		// Same endpoint binance.UseTestnet selects, without flipping the
+		// package-wide switch.
+		client.BaseURL = binance.BaseAPITestnetURL
+	}
 	log := logger.GetLogger()
-	log.Info("Initialized Binance client")
+	log.Info("Initialized Binance client", zap.String("environment", string(env)), zap.String("baseUrl", client.BaseURL))
 
-	return &BinanceExchange{client: client, log: log}, nil
+	return &BinanceExchange{client: client, symbols: newSymbolIndex(), log: log}, nil
 }
 
-func (b *BinanceExchange) CreateOrder(ctx context.Context, symbol, side, orderType string, quantity, price float64) (string, error, int) {
-	order, err := b.client.NewCreateOrderService().
-		Symbol(symbol).
-		Side(binance.SideType(side)).
-		Type(binance.OrderType(orderType)).
-		TimeInForce("GTC").
-		Quantity(fmt.Sprintf("%f", quantity)).
-		Price(fmt.Sprintf("%f", price)).
-		Do(ctx)
+func (b *BinanceExchange) CreateOrder(ctx context.Context, symbol models.Symbol, side, orderType string, quantity, price decimal.Decimal) (string, error) {
+	svc := b.client.NewCreateOrderService().
+		Symbol(binanceSymbol(symbol)).
+		Side(binance.SideType(strings.ToUpper(side))).
+		Type(binance.OrderType(strings.ToUpper(orderType))).
+		Quantity(quantity.String())
+	if !strings.EqualFold(orderType, "market") {
+		svc.TimeInForce(binance.TimeInForceTypeGTC).Price(price.String())
+	}
+	order, err := svc.Do(ctx)
 	if err != nil {
-		b.log.Error("Failed to create order", zap.String("symbol", symbol), zap.Error(err))
-		return "", err, 500
+		b.log.Error("Failed to create order", zap.String("symbol", symbol.String()), zap.Error(err))
+		return "", wrapBinanceError(err)
 	}
-	b.log.Info("Order created", zap.String("symbol", symbol), zap.Int64("orderId", order.OrderID))
-	return fmt.Sprintf("%d", order.OrderID), nil, 201
+	b.log.Info("Order created", zap.String("symbol", symbol.String()), zap.Int64("orderId", order.OrderID))
+	return fmt.Sprintf("%d", order.OrderID), nil
 }
 
-func (b *BinanceExchange) CancelOrder(ctx context.Context, symbol, orderID string) (error, int) {
+func (b *BinanceExchange) CancelOrder(ctx context.Context, symbol models.Symbol, orderID string) error {
+	if symbol.IsZero() {
+		return errs.New(errs.ErrInvalidRequest, "symbol is required to cancel binance orders")
+	}
 	id, err := strconv.ParseInt(orderID, 10, 64)
 	if err != nil {
 		b.log.Warn("Invalid order ID format", zap.String("orderId", orderID), zap.Error(err))
-		return err, 500
+		return errs.New(errs.ErrInvalidRequest, "invalid order ID %q", orderID)
 	}
 	_, err = b.client.NewCancelOrderService().
-		Symbol(symbol).
+		Symbol(binanceSymbol(symbol)).
 		OrderID(id).
 		Do(ctx)
 	if err != nil {
-		b.log.Error("Failed to cancel order", zap.String("symbol", symbol), zap.Int64("orderId", id), zap.Error(err))
+		b.log.Error("Failed to cancel order", zap.String("symbol", symbol.String()), zap.Int64("orderId", id), zap.Error(err))
+		return wrapBinanceError(err)
+	}
+	return nil
+}
+
+func (b *BinanceExchange) GetOrder(ctx context.Context, symbol models.Symbol, orderID string) (models.Order, error) {
+	if symbol.IsZero() {
+		return models.Order{}, errs.New(errs.ErrInvalidRequest, "symbol is required to look up binance orders")
+	}
+	id, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		b.log.Warn("Invalid order ID format", zap.String("orderId", orderID), zap.Error(err))
+		return models.Order{}, errs.New(errs.ErrInvalidRequest, "invalid order ID %q", orderID)
+	}
+
+	order, err := b.client.NewGetOrderService().
+		Symbol(binanceSymbol(symbol)).
+		OrderID(id).
+		Do(ctx)
+	if err != nil {
+		b.log.Error("Failed to get order", zap.String("symbol", symbol.String()), zap.Int64("orderId", id), zap.Error(err))
+		return models.Order{}, wrapBinanceError(err)
+	}
+	result, err := b.convertOrder(order)
+	if err != nil {
+		b.log.Error("Failed to parse order", zap.Int64("orderId", id), zap.Error(err))
+		return models.Order{}, err
+	}
+	return result, nil
+}
+
+func (b *BinanceExchange) ListOpenOrders(ctx context.Context, symbol models.Symbol) ([]models.Order, error) {
+	orders, err := b.client.NewListOpenOrdersService().Symbol(binanceSymbol(symbol)).Do(ctx)
+	if err != nil {
+		b.log.Error("Failed to list open orders", zap.String("symbol", symbol.String()), zap.Error(err))
+		return nil, wrapBinanceError(err)
+	}
+
+	result := make([]models.Order, 0, len(orders))
+	for _, o := range orders {
+		order, err := b.convertOrder(o)
+		if err != nil {
+			b.log.Error("Failed to parse order", zap.Int64("orderId", o.OrderID), zap.Error(err))
+			return nil, err
+		}
+		result = append(result, order)
+	}
+	return result, nil
+}
+
+func (b *BinanceExchange) ListOrderHistory(ctx context.Context, query models.OrderHistoryQuery) ([]models.Order, error) {
+	if query.Symbol.IsZero() {
+		return nil, errs.New(errs.ErrInvalidRequest, "symbol is required to list binance order history")
+	}
+
+	// allOrders has no paging, so fetch enough orders to cover the
+	// requested page and slice locally. One call returns at most
+	// binanceMaxOrderLimit orders: the most recent ones, or the oldest
+	// from StartTime onwards when it is set. A page past that cannot be
+	// served, so it is refused rather than coming back empty.
+	limit := query.Page * query.Limit
+	if limit > binanceMaxOrderLimit {
+		return nil, errs.New(errs.ErrInvalidRequest, "binance order history only reaches the first %d orders; narrow from/to instead of requesting page %d", binanceMaxOrderLimit, query.Page)
+	}
+	svc := b.client.NewListOrdersService().Symbol(binanceSymbol(query.Symbol)).Limit(limit)
+	if !query.StartTime.IsZero() {
+		svc.StartTime(query.StartTime.UnixMilli())
+	}
+	if !query.EndTime.IsZero() {
+		svc.EndTime(query.EndTime.UnixMilli())
+	}
+
+	orders, err := svc.Do(ctx)
+	if err != nil {
+		b.log.Error("Failed to list orders", zap.String("symbol", query.Symbol.String()), zap.Error(err))
+		return nil, wrapBinanceError(err)
+	}
+
+	result := make([]models.Order, 0, len(orders))
+	for _, o := range orders {
+		order, err := b.convertOrder(o)
+		if err != nil {
+			b.log.Error("Failed to parse order", zap.Int64("orderId", o.OrderID), zap.Error(err))
+			return nil, err
+		}
+		result = append(result, order)
+	}
+	return pageOrders(closedOrders(result), query.Page, query.Limit), nil
+}
+
+func (b *BinanceExchange) CancelAllOrders(ctx context.Context, symbol models.Symbol, side string) ([]models.CancelResult, error) {
+	if side != "" {
+		return cancelAllOpenOrders(ctx, b, symbol, side)
+	}
+
+	symbols := []models.Symbol{symbol}
+	if symbol.IsZero() {
+		open, err := b.ListOpenOrders(ctx, models.Symbol{})
+		if err != nil {
+			return nil, err
+		}
+		symbols = distinctSymbols(open)
+	}
+
+	var results []models.CancelResult
+	for _, sym := range symbols {
+		res, err := b.client.NewCancelOpenOrdersService().Symbol(binanceSymbol(sym)).Do(ctx)
+		if err != nil {
+			err = wrapBinanceError(err)
+			if errors.Is(err, errs.ErrOrderNotFound) {
+				continue
+			}
+			b.log.Error("Failed to cancel open orders", zap.String("symbol", sym.String()), zap.Error(err))
+			results = append(results, cancelResult("", sym, err))
+			continue
+		}
+		for _, o := range res.Orders {
+			results = append(results, cancelResult(strconv.FormatInt(o.OrderID, 10), b.symbols.lookup(o.Symbol), nil))
+		}
+	}
+	b.log.Info("Canceled open orders", zap.String("symbol", symbol.String()), zap.Int("count", len(results)))
+	return results, nil
+}
+
+func (b *BinanceExchange) GetFills(ctx context.Context, symbol models.Symbol, since time.Time) ([]models.Fill, error) {
+	if symbol.IsZero() {
+		return nil, errs.New(errs.ErrInvalidRequest, "symbol is required to list binance fills")
+	}
+
+	svc := b.client.NewListTradesService().
+		Symbol(binanceSymbol(symbol)).
+		StartTime(since.UnixMilli()).
+		Limit(binanceMaxTradeLimit)
+
+	var fills []models.Fill
+	for page := 0; page < binanceMaxTradePages; page++ {
+		trades, err := svc.Do(ctx)
+		if err != nil {
+			b.log.Error("Failed to list trades", zap.String("symbol", symbol.String()), zap.Error(err))
+			return nil, wrapBinanceError(err)
+		}
+		for _, t := range trades {
+			fill, err := b.convertTrade(t)
+			if err != nil {
+				b.log.Error("Failed to parse trade", zap.Int64("tradeId", t.ID), zap.Error(err))
+				return nil, err
+			}
+			fills = append(fills, fill)
+		}
+		if len(trades) < binanceMaxTradeLimit {
+			break
+		}
+		// startTime and fromId cannot be combined, so continue by trade ID.
+		svc = b.client.NewListTradesService().
+			Symbol(binanceSymbol(symbol)).
+			FromID(trades[len(trades)-1].ID + 1).
+			Limit(binanceMaxTradeLimit)
 	}
-	return err, 500
+
+	b.log.Info("Fetched fills", zap.String("symbol", symbol.String()), zap.Int("count", len(fills)))
+	return fills, nil
 }
 
-func (b *BinanceExchange) GetBalance(ctx context.Context, asset string) (float64, error, int) {
+func (b *BinanceExchange) GetBalance(ctx context.Context, asset string) (decimal.Decimal, error) {
 	account, err := b.client.NewGetAccountService().Do(ctx)
 	if err != nil {
 		b.log.Error("Failed to get account info", zap.Error(err))
-		return 0, err, 500
+		return decimal.Zero, wrapBinanceError(err)
 	}
 	for _, balance := range account.Balances {
 		if balance.Asset == asset {
-			free, err := strconv.ParseFloat(balance.Free, 64)
+			free, err := decimal.NewFromString(balance.Free)
 			if err != nil {
 				b.log.Error("Failed to parse balance", zap.String("asset", asset), zap.String("value", balance.Free), zap.Error(err))
-				return 0, err, 500
+				return decimal.Zero, errs.Wrap(errs.ErrInternal, err)
 			}
-			return free, nil, 200
+			return free, nil
 		}
 	}
 	b.log.Warn("Asset not found", zap.String("asset", asset))
-	return 0, fmt.Errorf("asset %s not found", asset), 404
+	return decimal.Zero, errs.New(errs.ErrAssetNotFound, "asset %s not found", asset)
 }
 
-func (b *BinanceExchange) GetOrderBook(ctx context.Context, symbol string) (models.OrderBook, error, int) {
-	res, err := b.client.NewDepthService().Symbol(symbol).Do(ctx)
+func (b *BinanceExchange) GetBalances(ctx context.Context) ([]models.Balance, error) {
+	account, err := b.client.NewGetAccountService().OmitZeroBalances(true).Do(ctx)
 	if err != nil {
-		b.log.Error("Failed to get order book", zap.String("symbol", symbol), zap.Error(err))
-		return models.OrderBook{}, fmt.Errorf("failed to get order book: %w", err), 500
+		b.log.Error("Failed to get account info", zap.Error(err))
+		return nil, wrapBinanceError(err)
 	}
 
-	bids := make([]models.OrderBookEntry, 0, len(res.Bids))
-	for _, bid := range res.Bids {
-		price, err := strconv.ParseFloat(bid.Price, 64)
+	var p decimals
+	var balances []models.Balance
+	for _, balance := range account.Balances {
+		balances = appendBalance(balances, balance.Asset, p.parse(balance.Free), p.parse(balance.Locked))
+	}
+	if p.err != nil {
+		b.log.Error("Failed to parse balances", zap.Error(p.err))
+		return nil, p.err
+	}
+	return balances, nil
+}
+
+func (b *BinanceExchange) GetOrderBook(ctx context.Context, symbol models.Symbol, limit int) (models.OrderBook, error) {
+	depth := b.client.NewDepthService().Symbol(binanceSymbol(symbol))
+	if limit > 0 {
+		depth = depth.Limit(nativeDepth(limit, 5, 10, 20, 50, 100, 500, 1000, 5000))
+	}
+	res, err := depth.Do(ctx)
+	if err != nil {
+		b.log.Error("Failed to get order book", zap.String("symbol", symbol.String()), zap.Error(err))
+		return models.OrderBook{}, fmt.Errorf("failed to get order book: %w", wrapBinanceError(err))
+	}
+
+	var p decimals
+	book := models.OrderBook{
+		Bids:     binanceEntries(&p, res.Bids),
+		Asks:     binanceEntries(&p, res.Asks),
+		Sequence: res.LastUpdateID,
+	}
+	if p.err != nil {
+		b.log.Error("Failed to parse order book", zap.String("symbol", symbol.String()), zap.Error(p.err))
+		return models.OrderBook{}, p.err
+	}
+
+	b.log.Info("Fetched order book", zap.String("symbol", symbol.String()), zap.Int("bids", len(book.Bids)), zap.Int("asks", len(book.Asks)))
+	return book, nil
+}
+
+func (b *BinanceExchange) GetTicker(ctx context.Context, symbol models.Symbol) (models.Ticker, error) {
+	stats, err := b.client.NewListPriceChangeStatsService().Symbol(binanceSymbol(symbol)).Do(ctx)
+	if err != nil {
+		b.log.Error("Failed to get ticker", zap.String("symbol", symbol.String()), zap.Error(err))
+		return models.Ticker{}, wrapBinanceError(err)
+	}
+	if len(stats) == 0 {
+		return models.Ticker{}, errs.New(errs.ErrSymbolNotFound, "no ticker for %s", symbol)
+	}
+
+	var p decimals
+	s := stats[0]
+	ticker := models.Ticker{
+		Symbol:             symbol,
+		LastPrice:          p.parse(s.LastPrice),
+		BidPrice:           p.parse(s.BidPrice),
+		AskPrice:           p.parse(s.AskPrice),
+		HighPrice:          p.parse(s.HighPrice),
+		LowPrice:           p.parse(s.LowPrice),
+		Volume:             p.parse(s.Volume),
+		QuoteVolume:        p.parse(s.QuoteVolume),
+		PriceChange:        p.parse(s.PriceChange),
+		PriceChangePercent: p.parse(s.PriceChangePercent),
+		Timestamp:          time.UnixMilli(s.CloseTime),
+	}
+	if p.err != nil {
+		b.log.Error("Failed to parse ticker", zap.String("symbol", symbol.String()), zap.Error(p.err))
+		return models.Ticker{}, p.err
+	}
+	return ticker, nil
+}
+
+func (b *BinanceExchange) GetCandles(ctx context.Context, symbol models.Symbol, interval models.CandleInterval, from, to time.Time, limit int) ([]models.Candle, error) {
+	return pageCandles(interval, from, to, limit, binanceMaxCandles, func(start, end time.Time, size int) ([]models.Candle, error) {
+		klines, err := b.client.NewKlinesService().
+			Symbol(binanceSymbol(symbol)).
+			Interval(string(interval)).
+			StartTime(start.UnixMilli()).
+			EndTime(end.UnixMilli() - 1).
+			Limit(size).
+			Do(ctx)
 		if err != nil {
-			b.log.Error("Failed to parse bid price", zap.String("price", bid.Price), zap.Error(err))
-			return models.OrderBook{}, fmt.Errorf("failed to parse bid price: %w", err), 500
+			b.log.Error("Failed to get candles", zap.String("symbol", symbol.String()), zap.Error(err))
+			return nil, wrapBinanceError(err)
 		}
-		quantity, err := strconv.ParseFloat(bid.Quantity, 64)
-		if err != nil {
-			b.log.Error("Failed to parse bid quantity", zap.String("quantity", bid.Quantity), zap.Error(err))
-			return models.OrderBook{}, fmt.Errorf("failed to parse bid quantity: %w", err), 500
+
+		var p decimals
+		candles := make([]models.Candle, 0, len(klines))
+		for _, k := range klines {
+			candles = append(candles, models.Candle{
+				OpenTime: time.UnixMilli(k.OpenTime),
+				Open:     p.parse(k.Open),
+				High:     p.parse(k.High),
+				Low:      p.parse(k.Low),
+				Close:    p.parse(k.Close),
+				Volume:   p.parse(k.Volume),
+			})
+		}
+		if p.err != nil {
+			b.log.Error("Failed to parse candles", zap.String("symbol", symbol.String()), zap.Error(p.err))
+			return nil, p.err
+		}
+		return candles, nil
+	})
+}
+
+func (b *BinanceExchange) GetRecentTrades(ctx context.Context, symbol models.Symbol, limit int) ([]models.Trade, error) {
+	res, err := b.client.NewRecentTradesService().
+		Symbol(binanceSymbol(symbol)).
+		Limit(min(limit, binanceMaxTrades)).
+		Do(ctx)
+	if err != nil {
+		b.log.Error("Failed to get recent trades", zap.String("symbol", symbol.String()), zap.Error(err))
+		return nil, wrapBinanceError(err)
+	}
+
+	var p decimals
+	trades := make([]models.Trade, 0, len(res))
+	for _, t := range res {
+		// The buyer resting as maker means the seller took.
+		side := "buy"
+		if t.IsBuyerMaker {
+			side = "sell"
 		}
-		bids = append(bids, models.OrderBookEntry{
-			Price:    price,
-			Quantity: quantity,
+		trades = append(trades, models.Trade{
+			TradeID:   strconv.FormatInt(t.ID, 10),
+			Price:     p.parse(t.Price),
+			Quantity:  p.parse(t.Quantity),
+			Side:      side,
+			Timestamp: time.UnixMilli(t.Time),
 		})
 	}
+	if p.err != nil {
+		b.log.Error("Failed to parse recent trades", zap.String("symbol", symbol.String()), zap.Error(p.err))
+		return nil, p.err
+	}
+	return trades, nil
+}
 
-	asks := make([]models.OrderBookEntry, 0, len(res.Asks))
-	for _, ask := range res.Asks {
-		price, err := strconv.ParseFloat(ask.Price, 64)
-		if err != nil {
-			b.log.Error("Failed to parse ask price", zap.String("price", ask.Price), zap.Error(err))
-			return models.OrderBook{}, fmt.Errorf("failed to parse ask price: %w", err), 500
+func (b *BinanceExchange) GetMarkets(ctx context.Context) ([]models.Market, error) {
+	info, err := b.client.NewExchangeInfoService().Do(ctx)
+	if err != nil {
+		b.log.Error("Failed to get exchange info", zap.Error(err))
+		return nil, wrapBinanceError(err)
+	}
+
+	var p decimals
+	markets := make([]models.Market, 0, len(info.Symbols))
+	for i := range info.Symbols {
+		s := &info.Symbols[i]
+		symbol := models.Symbol{Base: s.BaseAsset, Quote: s.QuoteAsset}
+		b.symbols.store(s.Symbol, symbol)
+		m := models.Market{
+			Symbol:   symbol,
+			Tradable: s.Status == "TRADING" && s.IsSpotTradingAllowed,
 		}
-		quantity, err := strconv.ParseFloat(ask.Quantity, 64)
-		if err != nil {
-			b.log.Error("Failed to parse ask quantity", zap.String("quantity", ask.Quantity), zap.Error(err))
-			return models.OrderBook{}, fmt.Errorf("failed to parse ask quantity: %w", err), 500
+		if f := s.PriceFilter(); f != nil {
+			m.TickSize = p.parse(f.TickSize)
 		}
-		asks = append(asks, models.OrderBookEntry{
-			Price:    price,
-			Quantity: quantity,
+		if f := s.LotSizeFilter(); f != nil {
+			m.StepSize = p.parse(f.StepSize)
+			m.MinQuantity = p.parse(f.MinQuantity)
+			m.MaxQuantity = p.parse(f.MaxQuantity)
+		}
+		if f := s.NotionalFilter(); f != nil {
+			m.MinNotional = p.parse(f.MinNotional)
+		}
+		markets = append(markets, m)
+	}
+	if p.err != nil {
+		b.log.Error("Failed to parse exchange info", zap.Error(p.err))
+		return nil, p.err
+	}
+
+	b.log.Info("Fetched markets", zap.Int("count", len(markets)))
+	return markets, nil
+}
+
+func (b *BinanceExchange) convertOrder(o *binance.Order) (models.Order, error) {
+	var p decimals
+	filled := p.parse(o.ExecutedQuantity)
+	return models.Order{
+		OrderID:        strconv.FormatInt(o.OrderID, 10),
+		Symbol:         b.symbols.lookup(o.Symbol),
+		Side:           strings.ToLower(string(o.Side)),
+		Type:           strings.ToLower(string(o.Type)),
+		Status:         binanceOrderStatus(o.Status),
+		Price:          p.parse(o.Price),
+		Quantity:       p.parse(o.OrigQuantity),
+		FilledQuantity: filled,
+		AvgFillPrice:   avgPrice(p.parse(o.CummulativeQuoteQuantity), filled),
+		CreatedAt:      time.UnixMilli(o.Time),
+		UpdatedAt:      time.UnixMilli(o.UpdateTime),
+	}, p.err
+}
+
+func (b *BinanceExchange) convertTrade(t *binance.TradeV3) (models.Fill, error) {
+	var p decimals
+	side := "sell"
+	if t.IsBuyer {
+		side = "buy"
+	}
+	return models.Fill{
+		TradeID:   strconv.FormatInt(t.ID, 10),
+		OrderID:   strconv.FormatInt(t.OrderID, 10),
+		Symbol:    b.symbols.lookup(t.Symbol),
+		Side:      side,
+		Price:     p.parse(t.Price),
+		Quantity:  p.parse(t.Quantity),
+		Fee:       p.parse(t.Commission),
+		FeeAsset:  t.CommissionAsset,
+		Liquidity: liquidity(t.IsMaker),
+		Timestamp: time.UnixMilli(t.Time),
+	}, p.err
+}
+
+func binanceEntries(p *decimals, levels []common.PriceLevel) []models.OrderBookEntry {
+	entries := make([]models.OrderBookEntry, 0, len(levels))
+	for _, level := range levels {
+		entries = append(entries, models.OrderBookEntry{
+			Price:    p.parse(level.Price),
+			Quantity: p.parse(level.Quantity),
 		})
 	}
+	return entries
+}
+
+func binanceSymbol(symbol models.Symbol) string {
+	return symbol.Join("")
+}
+
+func binanceOrderStatus(status binance.OrderStatusType) models.OrderStatus {
+	switch status {
+	case binance.OrderStatusTypePartiallyFilled:
+		return models.OrderStatusPartiallyFilled
+	case binance.OrderStatusTypeFilled:
+		return models.OrderStatusFilled
+	case binance.OrderStatusTypeCanceled, binance.OrderStatusTypePendingCancel,
+		binance.OrderStatusTypeExpired, binance.OrderStatusExpiredInMatch:
+		return models.OrderStatusCanceled
+	case binance.OrderStatusTypeRejected:
+		return models.OrderStatusRejected
+	default:
+		return models.OrderStatusNew
+	}
+}
+
+func wrapBinanceError(err error) error {
+	var apiErr *common.APIError
+	if !errors.As(err, &apiErr) {
+		return errs.Wrap(errs.ErrExchangeUnavailable, err)
+	}
 
-	b.log.Info("Fetched order book", zap.String("symbol", symbol), zap.Int("bids", len(bids)), zap.Int("asks", len(asks)))
-	return models.OrderBook{
-		Bids: bids,
-		Asks: asks,
-	}, nil, 200
+	switch apiErr.Code {
+	case -1003, -1015:
+		return errs.Wrap(errs.ErrRateLimited, err)
+	case -1002, -1021, -1022, -2014, -2015:
+		return errs.Wrap(errs.ErrAuthFailed, err)
+	case -1121:
+		return errs.Wrap(errs.ErrSymbolNotFound, err)
+	case -2011, -2013:
+		return errs.Wrap(errs.ErrOrderNotFound, err)
+	case -2010:
+		if strings.Contains(strings.ToLower(apiErr.Message), "insufficient") {
+			return errs.Wrap(errs.ErrInsufficientFunds, err)
+		}
+		return errs.Wrap(errs.ErrInvalidRequest, err)
+	case -1000, -1001, -1006, -1007, -1008, -1016:
+		return errs.Wrap(errs.ErrExchangeUnavailable, err)
+	}
+
+	if (apiErr.Code <= -1100 && apiErr.Code > -1200) || apiErr.Code == -1013 {
+		return errs.Wrap(errs.ErrInvalidRequest, err)
+	}
+	return errs.Wrap(errs.ErrExchangeUnavailable, err)
 }
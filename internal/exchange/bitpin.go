@@ -4,133 +4,209 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 
 	"eyeOne/config"
+	"eyeOne/internal/errs"
 	"eyeOne/internal/httpclient"
 	"eyeOne/models"
 )
 
+const bitpinMaxCandles = 1000
+
 type BitpinExchange struct {
-	baseURL   string
-	client    *httpclient.Client
-	logger    *zap.Logger
-	apiKey    string
-	secretKey string
+	baseURL string
+	client  *httpclient.Client
+	logger  *zap.Logger
+	tokens  *bitpinTokenManager
 }
 
 func NewBitpinExchange(client *httpclient.Client, logger *zap.Logger, cfg *config.Config) (*BitpinExchange, error) {
+	baseURL := "https://api.bitpin.ir"
+	if cfg.BitpinBaseURL != "" {
+		baseURL = strings.TrimSuffix(cfg.BitpinBaseURL, "/")
+	} else if !cfg.BitpinEnv.IsProduction() {
+		return nil, fmt.Errorf("bitpin has no public %s; set BITPIN_BASE_URL to its address", cfg.BitpinEnv)
+	}
+	logger.Info("Initialized Bitpin client", zap.String("environment", string(cfg.BitpinEnv)), zap.String("baseUrl", baseURL))
 	return &BitpinExchange{
-		baseURL:   "https://api.bitpin.ir",
-		client:    client,
-		logger:    logger,
-		apiKey:    cfg.BitpinAPIKey,
-		secretKey: cfg.BitpinSecretKey,
+		baseURL: baseURL,
+		client:  client,
+		logger:  logger,
+		tokens:  newBitpinTokenManager(baseURL, client, logger, cfg.BitpinAPIKey, cfg.BitpinSecretKey),
 	}, nil
 }
 
-func (b *BitpinExchange) AuthenticateBitpin(ctx context.Context) (struct {
-	Access  string `json:"access"`
-	Refresh string `json:"refresh"`
-}, error, int) {
-	url := fmt.Sprintf("%s/api/v1/usr/authenticate/", b.baseURL)
-	data := map[string]string{
-		"api_key":    b.apiKey,
-		"secret_key": b.secretKey,
-	}
+// GetOrderBook returns Bitpin's fixed-depth book; the venue takes no limit.
+func (b *BitpinExchange) GetOrderBook(ctx context.Context, symbol models.Symbol, limit int) (models.OrderBook, error) {
+	url := fmt.Sprintf("%s/api/v1/mth/orderbook/%s/", b.baseURL, bitpinSymbol(symbol))
 	headers := map[string]string{
 		"Content-Type": "application/json",
 	}
-	body, status, err := b.client.PostJSON(ctx, url, data, headers)
+	body, status, err := b.client.Get(ctx, url, headers)
 	if err != nil {
-		b.logger.Error("bitpin authentication failed", zap.Error(err))
-		return struct {
-			Access  string `json:"access"`
-			Refresh string `json:"refresh"`
-		}{}, err, status
+		b.logger.Error("failed to get order book", zap.Error(err))
+		return models.OrderBook{}, httpError("order book", status, body, err, errs.ErrSymbolNotFound)
 	}
 	if status < 200 || status >= 300 {
-		b.logger.Error("bitpin authentication failed with status", zap.Int("status", status), zap.ByteString("body", body))
-		return struct {
-			Access  string `json:"access"`
-			Refresh string `json:"refresh"`
-		}{}, fmt.Errorf("authentication failed with status %d", status), status
+		b.logger.Error("get order book failed", zap.Int("status", status), zap.ByteString("body", body))
+		return models.OrderBook{}, httpError("order book", status, body, nil, errs.ErrSymbolNotFound)
 	}
 
-	var tokenResp struct {
-		Access  string `json:"access"`
-		Refresh string `json:"refresh"`
-	}
-	if err := json.Unmarshal(body, &tokenResp); err != nil {
-		b.logger.Error("failed to unmarshal auth response", zap.Error(err))
-		return tokenResp, err, 500
+	var res models.BitpinOrderBookResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		b.logger.Error("failed to parse order book response", zap.Error(err))
+		return models.OrderBook{}, errs.Wrap(errs.ErrInternal, err)
 	}
-	return tokenResp, nil, 200
-}
 
-func (b *BitpinExchange) GetOrderBook(ctx context.Context, symbol string) (models.OrderBook, error, int) {
-	tokenResp, err, status := b.AuthenticateBitpin(ctx)
-	if err != nil {
-		return models.OrderBook{}, err, status
+	var p decimals
+	book := models.OrderBook{
+		Bids: convertEntries(&p, res.Bids),
+		Asks: convertEntries(&p, res.Asks),
 	}
+	if p.err != nil {
+		b.logger.Error("parse order book failed", zap.Error(p.err))
+		return models.OrderBook{}, p.err
+	}
+	return book, nil
+}
 
-	url := fmt.Sprintf("%s/api/v1/mth/orderbook/%s/", b.baseURL, symbol)
+// GetTicker reads the market from Bitpin's ticker list. The list has no
+// top of book or volume, so bid and ask come from the order book.
+func (b *BitpinExchange) GetTicker(ctx context.Context, symbol models.Symbol) (models.Ticker, error) {
+	url := fmt.Sprintf("%s/api/v1/mkt/tickers/", b.baseURL)
 	headers := map[string]string{
-		"Authorization": "Bearer " + tokenResp.Access,
-		"Content-Type":  "application/json",
+		"Content-Type": "application/json",
 	}
 	body, status, err := b.client.Get(ctx, url, headers)
-	if err != nil {
-		b.logger.Error("failed to get order book", zap.Error(err))
-		return models.OrderBook{}, err, status
-	}
-	if status < 200 || status >= 300 {
-		b.logger.Error("get order book failed", zap.Int("status", status), zap.ByteString("body", body))
-		return models.OrderBook{}, fmt.Errorf("order book error %d", status), status
+	if err != nil || status < 200 || status >= 300 {
+		b.logger.Error("get tickers failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return models.Ticker{}, httpError("tickers", status, body, err, errs.ErrSymbolNotFound)
 	}
 
-	var res models.BitpinOrderBookResponse
-	if err := json.Unmarshal(body, &res); err != nil {
-		b.logger.Error("failed to parse order book response", zap.Error(err))
-		return models.OrderBook{}, err, 500
+	var items []models.BitpinTickerResponse
+	if err := json.Unmarshal(body, &items); err != nil {
+		b.logger.Error("unmarshal tickers failed", zap.Error(err))
+		return models.Ticker{}, errs.Wrap(errs.ErrInternal, err)
 	}
 
-	return models.OrderBook{
-		Bids: models.ConvertToEntries(res.Bids),
-		Asks: models.ConvertToEntries(res.Asks),
-	}, nil, 200
+	native := bitpinSymbol(symbol)
+	for _, item := range items {
+		if item.Symbol != native {
+			continue
+		}
+		var p decimals
+		ticker := models.Ticker{
+			Symbol:    symbol,
+			LastPrice: p.parse(item.Price),
+			HighPrice: p.parse(item.High),
+			LowPrice:  p.parse(item.Low),
+			Timestamp: time.UnixMilli(int64(item.Timestamp * 1000)),
+		}
+		changeFromPercent(&ticker, p.parse(item.DailyChangePrice))
+		if p.err != nil {
+			b.logger.Error("parse ticker failed", zap.Error(p.err))
+			return models.Ticker{}, p.err
+		}
+
+		book, err := b.GetOrderBook(ctx, symbol, 1)
+		if err != nil {
+			return models.Ticker{}, err
+		}
+		if len(book.Bids) > 0 {
+			ticker.BidPrice = book.Bids[0].Price
+		}
+		if len(book.Asks) > 0 {
+			ticker.AskPrice = book.Asks[0].Price
+		}
+		return ticker, nil
+	}
+	return models.Ticker{}, errs.New(errs.ErrSymbolNotFound, "no ticker for %s", symbol)
 }
 
-func (b *BitpinExchange) CreateOrder(ctx context.Context, symbol, side, orderType string, quantity, price float64) (string, error, int) {
-	tokenResp, err, status := b.AuthenticateBitpin(ctx)
-	if err != nil {
-		return "", err, status
+func (b *BitpinExchange) GetCandles(ctx context.Context, symbol models.Symbol, interval models.CandleInterval, from, to time.Time, limit int) ([]models.Candle, error) {
+	headers := map[string]string{
+		"Content-Type": "application/json",
 	}
+	return pageCandles(interval, from, to, limit, bitpinMaxCandles, func(start, end time.Time, size int) ([]models.Candle, error) {
+		params := url.Values{
+			"symbol": {bitpinSymbol(symbol)},
+			"res":    {udfResolution(interval)},
+			"from":   {strconv.FormatInt(start.Unix(), 10)},
+			"to":     {strconv.FormatInt(end.Unix(), 10)},
+		}
+		endpoint := fmt.Sprintf("%s/api/v1/mkt/tv/get_bars/?%s", b.baseURL, params.Encode())
+		candles, err := getUDFHistory(ctx, b.client, endpoint, headers)
+		if err != nil {
+			b.logger.Error("get candles failed", zap.String("symbol", symbol.String()), zap.Error(err))
+		}
+		return candles, err
+	})
+}
 
-	url := fmt.Sprintf("%s/api/v1/odr/orders/", b.baseURL)
+func (b *BitpinExchange) GetRecentTrades(ctx context.Context, symbol models.Symbol, limit int) ([]models.Trade, error) {
+	url := fmt.Sprintf("%s/api/v1/mth/matches/%s/", b.baseURL, bitpinSymbol(symbol))
 	headers := map[string]string{
-		"Authorization": "Bearer " + tokenResp.Access,
-		"Content-Type":  "application/json",
+		"Content-Type": "application/json",
+	}
+	body, status, err := b.client.Get(ctx, url, headers)
+	if err != nil || status < 200 || status >= 300 {
+		b.logger.Error("get matches failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return nil, httpError("matches", status, body, err, errs.ErrSymbolNotFound)
+	}
+
+	var matches []models.BitpinMatchResponse
+	if err := json.Unmarshal(body, &matches); err != nil {
+		b.logger.Error("unmarshal matches failed", zap.Error(err))
+		return nil, errs.Wrap(errs.ErrInternal, err)
 	}
+
+	var p decimals
+	trades := make([]models.Trade, 0, len(matches))
+	for _, m := range matches {
+		trades = append(trades, models.Trade{
+			TradeID:   m.ID,
+			Price:     p.parse(m.Price),
+			Quantity:  p.parse(m.BaseAmount),
+			Side:      strings.ToLower(m.Side),
+			Timestamp: time.UnixMilli(int64(m.Time * 1000)),
+		})
+	}
+	if p.err != nil {
+		b.logger.Error("parse matches failed", zap.Error(p.err))
+		return nil, p.err
+	}
+	return trades, nil
+}
+
+func (b *BitpinExchange) CreateOrder(ctx context.Context, symbol models.Symbol, side, orderType string, quantity, price decimal.Decimal) (string, error) {
+	url := fmt.Sprintf("%s/api/v1/odr/orders/", b.baseURL)
 	payload := map[string]interface{}{
-		"symbol":           symbol,
+		"symbol":           bitpinSymbol(symbol),
 		"type":             orderType,
 		"side":             side,
-		"base_amount":      fmt.Sprintf("%.8f", quantity),
-		"price":            fmt.Sprintf("%.0f", price),
-		"quote_amount":     fmt.Sprintf("%.0f", quantity*price),
+		"base_amount":      quantity.String(),
+		"price":            price.String(),
+		"quote_amount":     quantity.Mul(price).String(),
 		"stop_price":       0,
 		"oco_target_price": 0,
 		"identifier":       uuid.NewString(),
 	}
 
-	respBody, status, err := b.client.PostJSON(ctx, url, payload, headers)
+	respBody, status, err := b.withToken(ctx, func(headers map[string]string) ([]byte, int, error) {
+		return b.client.PostJSON(ctx, url, payload, headers)
+	})
 	if err != nil || status < 200 || status >= 300 {
 		b.logger.Error("order creation failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", respBody))
-		return "", err, status
+		return "", httpError("order creation", status, respBody, err, errs.ErrSymbolNotFound)
 	}
 
 	var orderResp struct {
@@ -140,69 +216,307 @@ func (b *BitpinExchange) CreateOrder(ctx context.Context, symbol, side, orderTyp
 	}
 	if err := json.Unmarshal(respBody, &orderResp); err != nil {
 		b.logger.Error("unmarshal order response failed", zap.Error(err))
-		return "", err, 500
+		return "", errs.Wrap(errs.ErrInternal, err)
 	}
-	return fmt.Sprintf("%d", orderResp.Data.ID), nil, 201
+	return fmt.Sprintf("%d", orderResp.Data.ID), nil
 }
 
-func (b *BitpinExchange) CancelOrder(ctx context.Context, symbol, orderID string) (error, int) {
-	tokenResp, err, status := b.AuthenticateBitpin(ctx)
-	if err != nil {
-		return err, status
+func (b *BitpinExchange) CancelOrder(ctx context.Context, symbol models.Symbol, orderID string) error {
+	url := fmt.Sprintf("%s/api/v1/odr/orders/%s/", b.baseURL, orderID)
+	respBody, status, err := b.withToken(ctx, func(headers map[string]string) ([]byte, int, error) {
+		return b.client.Delete(ctx, url, headers)
+	})
+	if err != nil || status < 200 || status >= 300 {
+		b.logger.Error("cancel order failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", respBody))
+		return httpError("cancel order", status, respBody, err, errs.ErrOrderNotFound)
 	}
+	return nil
+}
 
+func (b *BitpinExchange) GetOrder(ctx context.Context, symbol models.Symbol, orderID string) (models.Order, error) {
 	url := fmt.Sprintf("%s/api/v1/odr/orders/%s/", b.baseURL, orderID)
-	headers := map[string]string{
-		"Authorization": "Bearer " + tokenResp.Access,
-		"Content-Type":  "application/json",
+	body, status, err := b.withToken(ctx, func(headers map[string]string) ([]byte, int, error) {
+		return b.client.Get(ctx, url, headers)
+	})
+	if err != nil || status < 200 || status >= 300 {
+		b.logger.Error("get order failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return models.Order{}, httpError("get order", status, body, err, errs.ErrOrderNotFound)
+	}
+
+	var order models.BitpinOrderResponse
+	if err := json.Unmarshal(body, &order); err != nil {
+		b.logger.Error("unmarshal order failed", zap.Error(err))
+		return models.Order{}, errs.Wrap(errs.ErrInternal, err)
+	}
+	result, err := convertBitpinOrder(order)
+	if err != nil {
+		b.logger.Error("parse order failed", zap.Error(err))
+		return models.Order{}, err
+	}
+	return result, nil
+}
+
+func (b *BitpinExchange) ListOpenOrders(ctx context.Context, symbol models.Symbol) ([]models.Order, error) {
+	params := url.Values{"state": {"active"}}
+	if !symbol.IsZero() {
+		params.Set("symbol", bitpinSymbol(symbol))
+	}
+	return b.listOrders(ctx, params)
+}
+
+func (b *BitpinExchange) ListOrderHistory(ctx context.Context, query models.OrderHistoryQuery) ([]models.Order, error) {
+	params := url.Values{
+		"state":  {"closed"},
+		"offset": {strconv.Itoa((query.Page - 1) * query.Limit)},
+		"limit":  {strconv.Itoa(query.Limit)},
+	}
+	if !query.Symbol.IsZero() {
+		params.Set("symbol", bitpinSymbol(query.Symbol))
+	}
+	if !query.StartTime.IsZero() {
+		params.Set("start", query.StartTime.UTC().Format(time.RFC3339))
+	}
+	if !query.EndTime.IsZero() {
+		params.Set("end", query.EndTime.UTC().Format(time.RFC3339))
+	}
+	return b.listOrders(ctx, params)
+}
+
+func (b *BitpinExchange) listOrders(ctx context.Context, params url.Values) ([]models.Order, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/odr/orders/?%s", b.baseURL, params.Encode())
+	body, status, err := b.withToken(ctx, func(headers map[string]string) ([]byte, int, error) {
+		return b.client.Get(ctx, endpoint, headers)
+	})
+	if err != nil || status < 200 || status >= 300 {
+		b.logger.Error("list orders failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return nil, httpError("list orders", status, body, err, errs.ErrSymbolNotFound)
+	}
+
+	var orders []models.BitpinOrderResponse
+	if err := json.Unmarshal(body, &orders); err != nil {
+		b.logger.Error("unmarshal orders failed", zap.Error(err))
+		return nil, errs.Wrap(errs.ErrInternal, err)
+	}
+
+	result := make([]models.Order, 0, len(orders))
+	for _, o := range orders {
+		order, err := convertBitpinOrder(o)
+		if err != nil {
+			b.logger.Error("parse order failed", zap.Error(err))
+			return nil, err
+		}
+		result = append(result, order)
+	}
+	return result, nil
+}
+
+func (b *BitpinExchange) CancelAllOrders(ctx context.Context, symbol models.Symbol, side string) ([]models.CancelResult, error) {
+	return cancelAllOpenOrders(ctx, b, symbol, side)
+}
+
+func (b *BitpinExchange) GetFills(ctx context.Context, symbol models.Symbol, since time.Time) ([]models.Fill, error) {
+	params := url.Values{"start": {since.UTC().Format(time.RFC3339)}}
+	if !symbol.IsZero() {
+		params.Set("symbol", bitpinSymbol(symbol))
 	}
-	respBody, status, err := b.client.Delete(ctx, url, headers)
+	endpoint := fmt.Sprintf("%s/api/v1/odr/fills/?%s", b.baseURL, params.Encode())
+	body, status, err := b.withToken(ctx, func(headers map[string]string) ([]byte, int, error) {
+		return b.client.Get(ctx, endpoint, headers)
+	})
 	if err != nil || status < 200 || status >= 300 {
-		b.logger.Error("cancel order failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", respBody))
-		return err, status
+		b.logger.Error("list fills failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return nil, httpError("list fills", status, body, err, errs.ErrSymbolNotFound)
+	}
+
+	var items []models.BitpinFillResponse
+	if err := json.Unmarshal(body, &items); err != nil {
+		b.logger.Error("unmarshal fills failed", zap.Error(err))
+		return nil, errs.Wrap(errs.ErrInternal, err)
+	}
+
+	var p decimals
+	fills := make([]models.Fill, 0, len(items))
+	for _, f := range items {
+		fills = append(fills, models.Fill{
+			TradeID:   strconv.FormatInt(f.ID, 10),
+			OrderID:   strconv.FormatInt(f.OrderID, 10),
+			Symbol:    splitSymbol(f.Symbol, "_"),
+			Side:      strings.ToLower(f.Side),
+			Price:     p.parse(f.Price),
+			Quantity:  p.parse(f.BaseAmount),
+			Fee:       p.parse(f.Commission),
+			FeeAsset:  f.CommissionAsset,
+			Liquidity: liquidity(f.IsMaker),
+			Timestamp: parseBitpinTime(f.CreatedAt),
+		})
 	}
-	return nil, 200
+	if p.err != nil {
+		b.logger.Error("parse fills failed", zap.Error(p.err))
+		return nil, p.err
+	}
+	return fills, nil
 }
 
-func (b *BitpinExchange) GetBalance(ctx context.Context, asset string) (float64, error, int) {
-	tokenResp, err, status := b.AuthenticateBitpin(ctx)
+func (b *BitpinExchange) GetBalance(ctx context.Context, asset string) (decimal.Decimal, error) {
+	wallets, err := b.wallets(ctx)
 	if err != nil {
-		return 0, err, status
+		return decimal.Zero, err
 	}
 
-	url := fmt.Sprintf("%s/api/v1/wlt/wallets/", b.baseURL)
-	headers := map[string]string{
-		"Authorization": "Bearer " + tokenResp.Access,
-		"Content-Type":  "application/json",
+	for _, w := range wallets {
+		if w.Asset == asset {
+			balance, err := decimal.NewFromString(w.Balance)
+			if err != nil {
+				b.logger.Error("parse balance failed", zap.String("balance", w.Balance), zap.Error(err))
+				return decimal.Zero, errs.Wrap(errs.ErrInternal, err)
+			}
+			return balance, nil
+		}
 	}
-	body, status, err := b.client.Get(ctx, url, headers)
+	return decimal.Zero, errs.New(errs.ErrAssetNotFound, "asset %s not found", asset)
+}
+
+func (b *BitpinExchange) GetBalances(ctx context.Context) ([]models.Balance, error) {
+	wallets, err := b.wallets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var p decimals
+	var balances []models.Balance
+	for _, w := range wallets {
+		balances = appendBalance(balances, w.Asset, p.parse(w.Balance), p.parse(w.Frozen))
+	}
+	if p.err != nil {
+		b.logger.Error("parse wallets failed", zap.Error(p.err))
+		return nil, p.err
+	}
+	return balances, nil
+}
+
+func (b *BitpinExchange) wallets(ctx context.Context) ([]models.BitpinWalletResponse, error) {
+	url := fmt.Sprintf("%s/api/v1/wlt/wallets/", b.baseURL)
+	body, status, err := b.withToken(ctx, func(headers map[string]string) ([]byte, int, error) {
+		return b.client.Get(ctx, url, headers)
+	})
 	if err != nil {
 		b.logger.Error("get wallets failed", zap.Error(err))
-		return 0, err, status
+		return nil, httpError("wallets", status, body, err, errs.ErrAssetNotFound)
 	}
 	if status < 200 || status >= 300 {
 		b.logger.Error("get wallets failed with status", zap.Int("status", status), zap.ByteString("body", body))
-		return 0, fmt.Errorf("wallets failed %d", status), status
+		return nil, httpError("wallets", status, body, nil, errs.ErrAssetNotFound)
 	}
 
-	var wallets []struct {
-		Asset   string `json:"asset"`
-		Balance string `json:"balance"`
-	}
+	var wallets []models.BitpinWalletResponse
 	if err := json.Unmarshal(body, &wallets); err != nil {
 		b.logger.Error("unmarshal wallets failed", zap.Error(err))
-		return 0, err, 500
+		return nil, errs.Wrap(errs.ErrInternal, err)
 	}
+	return wallets, nil
+}
 
-	for _, w := range wallets {
-		if w.Asset == asset {
-			balance, err := strconv.ParseFloat(w.Balance, 64)
-			if err != nil {
-				b.logger.Error("parse balance failed", zap.String("balance", w.Balance), zap.Error(err))
-				return 0, err, status
-			}
-			return balance, nil, status
+func (b *BitpinExchange) GetMarkets(ctx context.Context) ([]models.Market, error) {
+	url := fmt.Sprintf("%s/api/v1/mkt/markets/", b.baseURL)
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+	body, status, err := b.client.Get(ctx, url, headers)
+	if err != nil || status < 200 || status >= 300 {
+		b.logger.Error("get markets failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return nil, httpError("markets", status, body, err, errs.ErrExchangeUnavailable)
+	}
+
+	var items []models.BitpinMarketResponse
+	if err := json.Unmarshal(body, &items); err != nil {
+		b.logger.Error("unmarshal markets failed", zap.Error(err))
+		return nil, errs.Wrap(errs.ErrInternal, err)
+	}
+
+	var p decimals
+	markets := make([]models.Market, 0, len(items))
+	for _, m := range items {
+		markets = append(markets, models.Market{
+			Symbol:      models.Symbol{Base: m.Base, Quote: m.Quote},
+			Tradable:    m.Tradable,
+			TickSize:    models.PrecisionStep(m.PricePrecision),
+			StepSize:    models.PrecisionStep(m.BaseAmountPrecision),
+			MinQuantity: p.parse(m.MinBaseAmount),
+			MinNotional: p.parse(m.MinQuoteAmount),
+		})
+	}
+	if p.err != nil {
+		b.logger.Error("parse markets failed", zap.Error(p.err))
+		return nil, p.err
+	}
+	return markets, nil
+}
+
+// convertBitpinOrder maps an order. Market orders carry no price, and
+// market buys placed by quote amount no base amount.
+func convertBitpinOrder(o models.BitpinOrderResponse) (models.Order, error) {
+	var p decimals
+	quantity := p.optional(o.BaseAmount)
+	filled := p.parse(o.DealedBaseAmount)
+	createdAt := parseBitpinTime(o.CreatedAt)
+	updatedAt := parseBitpinTime(o.ClosedAt)
+	if updatedAt.IsZero() {
+		updatedAt = createdAt
+	}
+	return models.Order{
+		OrderID:        strconv.FormatInt(o.ID, 10),
+		Symbol:         splitSymbol(o.Symbol, "_"),
+		Side:           strings.ToLower(o.Side),
+		Type:           strings.ToLower(o.Type),
+		Status:         bitpinOrderStatus(o.State, quantity, filled),
+		Price:          p.optional(o.Price),
+		Quantity:       quantity,
+		FilledQuantity: filled,
+		AvgFillPrice:   avgPrice(p.parse(o.DealedQuoteAmount), filled),
+		CreatedAt:      createdAt,
+		UpdatedAt:      updatedAt,
+	}, p.err
+}
+
+func bitpinSymbol(symbol models.Symbol) string {
+	return symbol.Join("_")
+}
+
+// bitpinOrderStatus maps an order's state. Bitpin reports cancelled orders
+// as closed too, so a closed order only counts as filled once its whole
+// quantity has been dealt; otherwise it is canceled, and FilledQuantity
+// shows any partial fill.
+func bitpinOrderStatus(state string, quantity, filled decimal.Decimal) models.OrderStatus {
+	switch strings.ToLower(state) {
+	case "closed", "done":
+		if filled.IsPositive() && filled.GreaterThanOrEqual(quantity) {
+			return models.OrderStatusFilled
 		}
+		return models.OrderStatusCanceled
+	case "filled":
+		return models.OrderStatusFilled
+	case "canceled", "cancelled", "expired":
+		return models.OrderStatusCanceled
+	case "rejected", "failed":
+		return models.OrderStatusRejected
+	}
+	if filled.IsPositive() {
+		return models.OrderStatusPartiallyFilled
+	}
+	return models.OrderStatusNew
+}
+
+func parseBitpinTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func bitpinAuthError(status int, body []byte, err error) error {
+	if status >= 400 && status < 500 && status != http.StatusTooManyRequests {
+		return httpError("authentication", http.StatusUnauthorized, body, err, errs.ErrAuthFailed)
 	}
-	return 0, fmt.Errorf("asset %s not found", asset), status
+	return httpError("authentication", status, body, err, errs.ErrAuthFailed)
 }
@@ -1,6 +1,230 @@
 package exchange
 
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"eyeOne/internal/errs"
+	"eyeOne/internal/httpclient"
+)
+
+const (
+	// Lifetimes assumed when a token is not a JWT with an exp claim.
+	bitpinAccessTTL  = 15 * time.Minute
+	bitpinRefreshTTL = 24 * time.Hour
+
+	// Tokens are renewed this long before they expire so that a request
+	// never leaves with a token that dies in flight.
+	bitpinTokenSkew = 30 * time.Second
+
+	bitpinAuthTimeout = 10 * time.Second
+)
+
 type TokenResponse struct {
 	Access  string `json:"access"`
 	Refresh string `json:"refresh"`
 }
+
+// bitpinTokenManager caches the Bitpin access token and renews it with the
+// refresh token, falling back to an API-key login. Concurrent callers that
+// find the token stale share a single renewal.
+type bitpinTokenManager struct {
+	baseURL   string
+	client    *httpclient.Client
+	logger    *zap.Logger
+	apiKey    string
+	secretKey string
+	now       func() time.Time
+
+	mu         sync.Mutex
+	access     string
+	accessExp  time.Time
+	refresh    string
+	refreshExp time.Time
+	inflight   *tokenCall
+}
+
+type tokenCall struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+func newBitpinTokenManager(baseURL string, client *httpclient.Client, logger *zap.Logger, apiKey, secretKey string) *bitpinTokenManager {
+	return &bitpinTokenManager{
+		baseURL:   baseURL,
+		client:    client,
+		logger:    logger,
+		apiKey:    apiKey,
+		secretKey: secretKey,
+		now:       time.Now,
+	}
+}
+
+// Token returns a valid access token, renewing it if needed.
+func (m *bitpinTokenManager) Token(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	if m.access != "" && m.now().Before(m.accessExp) {
+		token := m.access
+		m.mu.Unlock()
+		return token, nil
+	}
+
+	call := m.inflight
+	if call == nil {
+		call = &tokenCall{done: make(chan struct{})}
+		m.inflight = call
+		refresh := ""
+		if m.refresh != "" && m.now().Before(m.refreshExp) {
+			refresh = m.refresh
+		}
+		// The renewal is shared, so it must not be canceled together with
+		// the request that happened to start it.
+		go m.renew(context.WithoutCancel(ctx), call, refresh)
+	}
+	m.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.token, call.err
+	case <-ctx.Done():
+		return "", errs.Wrap(errs.ErrExchangeUnavailable, ctx.Err())
+	}
+}
+
+// Invalidate drops token after the exchange rejected it. Tokens that were
+// already replaced by a concurrent renewal are left alone.
+func (m *bitpinTokenManager) Invalidate(token string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.access == token {
+		m.access = ""
+	}
+}
+
+func (m *bitpinTokenManager) renew(ctx context.Context, call *tokenCall, refresh string) {
+	ctx, cancel := context.WithTimeout(ctx, bitpinAuthTimeout)
+	defer cancel()
+
+	var (
+		tokens TokenResponse
+		err    error
+	)
+	if refresh != "" {
+		tokens, err = m.refreshAccess(ctx, refresh)
+		if err != nil {
+			m.logger.Warn("bitpin token refresh failed, logging in again", zap.Error(err))
+		}
+	}
+	if refresh == "" || err != nil {
+		tokens, err = m.login(ctx)
+	}
+
+	m.mu.Lock()
+	if err == nil {
+		now := m.now()
+		m.access = tokens.Access
+		m.accessExp = tokenExpiry(tokens.Access, now, bitpinAccessTTL).Add(-bitpinTokenSkew)
+		if tokens.Refresh != "" {
+			m.refresh = tokens.Refresh
+			m.refreshExp = tokenExpiry(tokens.Refresh, now, bitpinRefreshTTL).Add(-bitpinTokenSkew)
+		}
+	}
+	call.token, call.err = tokens.Access, err
+	m.inflight = nil
+	m.mu.Unlock()
+	close(call.done)
+}
+
+func (m *bitpinTokenManager) login(ctx context.Context) (TokenResponse, error) {
+	url := fmt.Sprintf("%s/api/v1/usr/authenticate/", m.baseURL)
+	data := map[string]string{
+		"api_key":    m.apiKey,
+		"secret_key": m.secretKey,
+	}
+	return m.requestTokens(ctx, url, data)
+}
+
+func (m *bitpinTokenManager) refreshAccess(ctx context.Context, refresh string) (TokenResponse, error) {
+	url := fmt.Sprintf("%s/api/v1/usr/refresh_token/", m.baseURL)
+	data := map[string]string{
+		"refresh": refresh,
+	}
+	return m.requestTokens(ctx, url, data)
+}
+
+func (m *bitpinTokenManager) requestTokens(ctx context.Context, url string, data map[string]string) (TokenResponse, error) {
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+	body, status, err := m.client.PostJSON(ctx, url, data, headers)
+	if err != nil {
+		m.logger.Error("bitpin authentication failed", zap.Int("status", status), zap.Error(err))
+		return TokenResponse{}, bitpinAuthError(status, body, err)
+	}
+
+	var tokens TokenResponse
+	if err := json.Unmarshal(body, &tokens); err != nil {
+		m.logger.Error("failed to unmarshal auth response", zap.Error(err))
+		return TokenResponse{}, errs.Wrap(errs.ErrInternal, err)
+	}
+	if tokens.Access == "" {
+		return TokenResponse{}, errs.New(errs.ErrAuthFailed, "bitpin returned no access token")
+	}
+	return tokens, nil
+}
+
+// tokenExpiry reads the exp claim of a JWT, or assumes ttl for opaque tokens.
+func tokenExpiry(token string, now time.Time, ttl time.Duration) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return now.Add(ttl)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return now.Add(ttl)
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return now.Add(ttl)
+	}
+	return time.Unix(claims.Exp, 0)
+}
+
+// withToken runs an authenticated request. When Bitpin rejects the token
+// with 401 it is renewed and the request retried exactly once.
+func (b *BitpinExchange) withToken(ctx context.Context, do func(headers map[string]string) ([]byte, int, error)) ([]byte, int, error) {
+	token, err := b.tokens.Token(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	body, status, err := do(bitpinAuthHeaders(token))
+	if status != http.StatusUnauthorized {
+		return body, status, err
+	}
+
+	b.logger.Warn("bitpin rejected access token, authenticating again")
+	b.tokens.Invalidate(token)
+	token, err = b.tokens.Token(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return do(bitpinAuthHeaders(token))
+}
+
+func bitpinAuthHeaders(token string) map[string]string {
+	return map[string]string{
+		"Authorization": "Bearer " + token,
+		"Content-Type":  "application/json",
+	}
+}
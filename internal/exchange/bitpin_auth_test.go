@@ -0,0 +1,128 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"eyeOne/config"
+	"eyeOne/internal/errs"
+	"eyeOne/models"
+)
+
+// bitpinAuthServer answers Bitpin's login and refresh endpoints with
+// numbered opaque tokens and counts the calls to each.
+type bitpinAuthServer struct {
+	logins    atomic.Int32
+	refreshes atomic.Int32
+	delay     time.Duration
+}
+
+func (s *bitpinAuthServer) serve(rw http.ResponseWriter, r *http.Request) bool {
+	var tokens TokenResponse
+	switch r.URL.Path {
+	case "/api/v1/usr/authenticate/":
+		n := s.logins.Add(1)
+		tokens = TokenResponse{Access: fmt.Sprintf("access-%d", n), Refresh: fmt.Sprintf("refresh-%d", n)}
+	case "/api/v1/usr/refresh_token/":
+		var req map[string]string
+		json.NewDecoder(r.Body).Decode(&req)
+		n := s.refreshes.Add(1)
+		tokens = TokenResponse{Access: fmt.Sprintf("refreshed-%d-from-%s", n, req["refresh"])}
+	default:
+		return false
+	}
+	time.Sleep(s.delay)
+	json.NewEncoder(rw).Encode(tokens)
+	return true
+}
+
+func newBitpinTestExchange(t *testing.T, auth *bitpinAuthServer, handler http.HandlerFunc) *BitpinExchange {
+	return newTestAdapter(t, func(rw http.ResponseWriter, r *http.Request) {
+		if !auth.serve(rw, r) {
+			handler(rw, r)
+		}
+	}, NewBitpinExchange, func(baseURL string) *config.Config {
+		return &config.Config{BitpinEnv: models.EnvironmentSandbox, BitpinBaseURL: baseURL, BitpinAPIKey: "key", BitpinSecretKey: "secret"}
+	})
+}
+
+func TestBitpinTokenConcurrentCallersShareOneLogin(t *testing.T) {
+	auth := &bitpinAuthServer{delay: 50 * time.Millisecond}
+	b := newBitpinTestExchange(t, auth, http.NotFound)
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 20)
+	failures := make([]error, len(tokens))
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], failures[i] = b.tokens.Token(context.Background())
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range tokens {
+		if failures[i] != nil || tokens[i] != "access-1" {
+			t.Errorf("caller %d got %q, %v; want access-1", i, tokens[i], failures[i])
+		}
+	}
+	if n := auth.logins.Load(); n != 1 {
+		t.Errorf("%d logins, want 1", n)
+	}
+}
+
+func TestBitpinTokenRenewsExpiredAccessWithRefresh(t *testing.T) {
+	auth := &bitpinAuthServer{}
+	b := newBitpinTestExchange(t, auth, http.NotFound)
+	now := time.Now()
+	b.tokens.now = func() time.Time { return now }
+
+	if _, err := b.tokens.Token(context.Background()); err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+
+	// Past the access token's lifetime, well within the refresh token's.
+	now = now.Add(bitpinAccessTTL)
+	token, err := b.tokens.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token after expiry: %v", err)
+	}
+	if token != "refreshed-1-from-refresh-1" {
+		t.Errorf("token = %q, want one refreshed from refresh-1", token)
+	}
+	if logins, refreshes := auth.logins.Load(), auth.refreshes.Load(); logins != 1 || refreshes != 1 {
+		t.Errorf("%d logins and %d refreshes, want 1 and 1", logins, refreshes)
+	}
+}
+
+func TestBitpinRetriesRejectedTokenOnce(t *testing.T) {
+	auth := &bitpinAuthServer{}
+	var (
+		mu     sync.Mutex
+		tokens []string
+	)
+	b := newBitpinTestExchange(t, auth, func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		tokens = append(tokens, r.Header.Get("Authorization"))
+		mu.Unlock()
+		rw.WriteHeader(http.StatusUnauthorized)
+		rw.Write([]byte(`{"detail":"Given token not valid for any token type"}`))
+	})
+
+	_, err := b.GetBalances(context.Background())
+	if !errors.Is(err, errs.ErrAuthFailed) {
+		t.Errorf("error = %v, want %v", err, errs.ErrAuthFailed)
+	}
+	want := []string{"Bearer access-1", "Bearer refreshed-1-from-refresh-1"}
+	if len(tokens) != len(want) || tokens[0] != want[0] || tokens[1] != want[1] {
+		t.Errorf("requests sent %q, want the original and one retry with a renewed token %q", tokens, want)
+	}
+}
@@ -0,0 +1,612 @@
+package exchange
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"eyeOne/config"
+	"eyeOne/internal/errs"
+	"eyeOne/internal/httpclient"
+	"eyeOne/models"
+)
+
+const (
+	bybitRecvWindow     = "5000"
+	bybitBookDepth      = 200
+	bybitHistoryLimit   = 50
+	bybitMaxHistoryPage = 10
+	bybitMaxCandles     = 1000
+	bybitMaxTrades      = 60
+)
+
+type BybitExchange struct {
+	baseURL string
+	client  *httpclient.Client
+	signer  httpclient.Signer
+	symbols *symbolIndex
+	logger  *zap.Logger
+}
+
+func NewBybitExchange(client *httpclient.Client, logger *zap.Logger, cfg *config.Config) (*BybitExchange, error) {
+	baseURL := "https://api.bybit.com"
+	if !cfg.BybitEnv.IsProduction() {
+		baseURL = "https://api-testnet.bybit.com"
+	}
+	logger.Info("Initialized Bybit client", zap.String("environment", string(cfg.BybitEnv)), zap.String("baseUrl", baseURL))
+	b := &BybitExchange{
+		baseURL: baseURL,
+		client:  client,
+		symbols: newSymbolIndex(),
+		logger:  logger,
+	}
+	if cfg.HasBybitCredentials() {
+		b.signer = newBybitSigner(cfg.BybitAPIKey, cfg.BybitSecretKey)
+	}
+	return b, nil
+}
+
+// newBybitSigner signs timestamp + key + recv window + query string (GET)
+// or body (POST) with HMAC-SHA256, hex encoded.
+func newBybitSigner(apiKey, secretKey string) httpclient.Signer {
+	return &httpclient.HMACSigner{
+		Secret: []byte(secretKey),
+		Hash:   sha256.New,
+		Encode: hex.EncodeToString,
+		Timestamp: func(t time.Time) string {
+			return strconv.FormatInt(t.UnixMilli(), 10)
+		},
+		Message: func(timestamp string, req *http.Request, body []byte) string {
+			payload := req.URL.RawQuery
+			if req.Method != http.MethodGet {
+				payload = string(body)
+			}
+			return timestamp + apiKey + bybitRecvWindow + payload
+		},
+		Apply: func(header http.Header, timestamp, signature string) {
+			header.Set("X-BAPI-API-KEY", apiKey)
+			header.Set("X-BAPI-TIMESTAMP", timestamp)
+			header.Set("X-BAPI-SIGN", signature)
+			header.Set("X-BAPI-RECV-WINDOW", bybitRecvWindow)
+		},
+	}
+}
+
+func (b *BybitExchange) GetOrderBook(ctx context.Context, symbol models.Symbol, limit int) (models.OrderBook, error) {
+	depth := bybitBookDepth
+	if limit > 0 {
+		depth = min(limit, bybitBookDepth)
+	}
+	params := url.Values{
+		"category": {"spot"},
+		"symbol":   {bybitSymbol(symbol)},
+		"limit":    {strconv.Itoa(depth)},
+	}
+	var book models.BybitOrderBookResponse
+	if err := b.call(ctx, http.MethodGet, "/v5/market/orderbook", params, nil, &book, errs.ErrSymbolNotFound); err != nil {
+		b.logger.Error("get order book failed", zap.String("symbol", symbol.String()), zap.Error(err))
+		return models.OrderBook{}, err
+	}
+
+	var p decimals
+	result := models.OrderBook{
+		Bids:      convertEntries(&p, book.Bids),
+		Asks:      convertEntries(&p, book.Asks),
+		Sequence:  book.Update,
+		Timestamp: time.UnixMilli(book.Ts),
+	}
+	if p.err != nil {
+		b.logger.Error("parse order book failed", zap.String("symbol", symbol.String()), zap.Error(p.err))
+		return models.OrderBook{}, p.err
+	}
+	return result, nil
+}
+
+func (b *BybitExchange) GetTicker(ctx context.Context, symbol models.Symbol) (models.Ticker, error) {
+	params := url.Values{
+		"category": {"spot"},
+		"symbol":   {bybitSymbol(symbol)},
+	}
+	var res struct {
+		List []models.BybitTickerResponse `json:"list"`
+	}
+	if err := b.call(ctx, http.MethodGet, "/v5/market/tickers", params, nil, &res, errs.ErrSymbolNotFound); err != nil {
+		b.logger.Error("get ticker failed", zap.String("symbol", symbol.String()), zap.Error(err))
+		return models.Ticker{}, err
+	}
+	if len(res.List) == 0 {
+		return models.Ticker{}, errs.New(errs.ErrSymbolNotFound, "no ticker for %s", symbol)
+	}
+
+	var p decimals
+	t := res.List[0]
+	ticker := models.Ticker{
+		Symbol:      symbol,
+		LastPrice:   p.parse(t.LastPrice),
+		BidPrice:    p.parse(t.Bid1Price),
+		AskPrice:    p.parse(t.Ask1Price),
+		HighPrice:   p.parse(t.HighPrice24h),
+		LowPrice:    p.parse(t.LowPrice24h),
+		Volume:      p.parse(t.Volume24h),
+		QuoteVolume: p.parse(t.Turnover24h),
+		Timestamp:   time.Now(),
+	}
+	changeFromOpen(&ticker, p.parse(t.PrevPrice24h))
+	if p.err != nil {
+		b.logger.Error("parse ticker failed", zap.String("symbol", symbol.String()), zap.Error(p.err))
+		return models.Ticker{}, p.err
+	}
+	return ticker, nil
+}
+
+var bybitIntervals = map[models.CandleInterval]string{
+	models.CandleInterval1m:  "1",
+	models.CandleInterval5m:  "5",
+	models.CandleInterval15m: "15",
+	models.CandleInterval1h:  "60",
+	models.CandleInterval4h:  "240",
+	models.CandleInterval1d:  "D",
+}
+
+func (b *BybitExchange) GetCandles(ctx context.Context, symbol models.Symbol, interval models.CandleInterval, from, to time.Time, limit int) ([]models.Candle, error) {
+	return pageCandles(interval, from, to, limit, bybitMaxCandles, func(start, end time.Time, size int) ([]models.Candle, error) {
+		params := url.Values{
+			"category": {"spot"},
+			"symbol":   {bybitSymbol(symbol)},
+			"interval": {bybitIntervals[interval]},
+			"start":    {strconv.FormatInt(start.UnixMilli(), 10)},
+			"end":      {strconv.FormatInt(end.UnixMilli()-1, 10)},
+			"limit":    {strconv.Itoa(size)},
+		}
+		var res struct {
+			List [][]string `json:"list"`
+		}
+		if err := b.call(ctx, http.MethodGet, "/v5/market/kline", params, nil, &res, errs.ErrSymbolNotFound); err != nil {
+			b.logger.Error("get candles failed", zap.String("symbol", symbol.String()), zap.Error(err))
+			return nil, err
+		}
+		return rowCandles(res.List)
+	})
+}
+
+func (b *BybitExchange) GetRecentTrades(ctx context.Context, symbol models.Symbol, limit int) ([]models.Trade, error) {
+	params := url.Values{
+		"category": {"spot"},
+		"symbol":   {bybitSymbol(symbol)},
+		"limit":    {strconv.Itoa(min(limit, bybitMaxTrades))},
+	}
+	var res struct {
+		List []models.BybitTradeResponse `json:"list"`
+	}
+	if err := b.call(ctx, http.MethodGet, "/v5/market/recent-trade", params, nil, &res, errs.ErrSymbolNotFound); err != nil {
+		b.logger.Error("get recent trades failed", zap.String("symbol", symbol.String()), zap.Error(err))
+		return nil, err
+	}
+
+	var p decimals
+	trades := make([]models.Trade, 0, len(res.List))
+	for _, t := range res.List {
+		trades = append(trades, models.Trade{
+			TradeID:   t.ExecID,
+			Price:     p.parse(t.Price),
+			Quantity:  p.parse(t.Size),
+			Side:      strings.ToLower(t.Side),
+			Timestamp: parseMillis(t.Time),
+		})
+	}
+	if p.err != nil {
+		b.logger.Error("parse recent trades failed", zap.String("symbol", symbol.String()), zap.Error(p.err))
+		return nil, p.err
+	}
+	return trades, nil
+}
+
+func (b *BybitExchange) CreateOrder(ctx context.Context, symbol models.Symbol, side, orderType string, quantity, price decimal.Decimal) (string, error) {
+	payload := map[string]string{
+		"category":  "spot",
+		"symbol":    bybitSymbol(symbol),
+		"side":      bybitCase(side),
+		"orderType": bybitCase(orderType),
+		"qty":       quantity.String(),
+	}
+	if strings.EqualFold(orderType, "market") {
+		// Spot market buys are sized in the quote coin by default.
+		payload["marketUnit"] = "baseCoin"
+	} else {
+		payload["price"] = price.String()
+	}
+
+	var res struct {
+		OrderID string `json:"orderId"`
+	}
+	if err := b.call(ctx, http.MethodPost, "/v5/order/create", nil, payload, &res, errs.ErrSymbolNotFound); err != nil {
+		b.logger.Error("order creation failed", zap.String("symbol", symbol.String()), zap.Error(err))
+		return "", err
+	}
+	b.logger.Info("Order created", zap.String("symbol", symbol.String()), zap.String("orderId", res.OrderID))
+	return res.OrderID, nil
+}
+
+func (b *BybitExchange) CancelOrder(ctx context.Context, symbol models.Symbol, orderID string) error {
+	if symbol.IsZero() {
+		return errs.New(errs.ErrInvalidRequest, "symbol is required to cancel bybit orders")
+	}
+	payload := map[string]string{
+		"category": "spot",
+		"symbol":   bybitSymbol(symbol),
+		"orderId":  orderID,
+	}
+	var res struct{}
+	if err := b.call(ctx, http.MethodPost, "/v5/order/cancel", nil, payload, &res, errs.ErrOrderNotFound); err != nil {
+		b.logger.Error("cancel order failed", zap.String("orderId", orderID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (b *BybitExchange) GetOrder(ctx context.Context, symbol models.Symbol, orderID string) (models.Order, error) {
+	params := url.Values{
+		"category": {"spot"},
+		"orderId":  {orderID},
+	}
+	if !symbol.IsZero() {
+		params.Set("symbol", bybitSymbol(symbol))
+	}
+
+	// Open orders are served by the realtime endpoint, closed ones by history.
+	for _, path := range []string{"/v5/order/realtime", "/v5/order/history"} {
+		orders, _, err := b.orders(ctx, path, params)
+		if err != nil {
+			b.logger.Error("get order failed", zap.String("orderId", orderID), zap.Error(err))
+			return models.Order{}, err
+		}
+		if len(orders) > 0 {
+			return orders[0], nil
+		}
+	}
+	return models.Order{}, errs.New(errs.ErrOrderNotFound, "order %s not found", orderID)
+}
+
+func (b *BybitExchange) ListOpenOrders(ctx context.Context, symbol models.Symbol) ([]models.Order, error) {
+	params := url.Values{"category": {"spot"}}
+	if !symbol.IsZero() {
+		params.Set("symbol", bybitSymbol(symbol))
+	}
+	orders, _, err := b.orders(ctx, "/v5/order/realtime", params)
+	if err != nil {
+		b.logger.Error("list open orders failed", zap.Error(err))
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (b *BybitExchange) ListOrderHistory(ctx context.Context, query models.OrderHistoryQuery) ([]models.Order, error) {
+	params := url.Values{
+		"category": {"spot"},
+		"limit":    {strconv.Itoa(bybitHistoryLimit)},
+	}
+	if !query.Symbol.IsZero() {
+		params.Set("symbol", bybitSymbol(query.Symbol))
+	}
+	if !query.StartTime.IsZero() {
+		params.Set("startTime", strconv.FormatInt(query.StartTime.UnixMilli(), 10))
+	}
+	if !query.EndTime.IsZero() {
+		params.Set("endTime", strconv.FormatInt(query.EndTime.UnixMilli(), 10))
+	}
+
+	want := query.Page * query.Limit
+	var result []models.Order
+	for page := 0; page < bybitMaxHistoryPage && len(result) < want; page++ {
+		orders, cursor, err := b.orders(ctx, "/v5/order/history", params)
+		if err != nil {
+			b.logger.Error("list order history failed", zap.Error(err))
+			return nil, err
+		}
+		result = append(result, orders...)
+		if cursor == "" {
+			break
+		}
+		params.Set("cursor", cursor)
+	}
+	return pageOrders(closedOrders(result), query.Page, query.Limit), nil
+}
+
+func (b *BybitExchange) orders(ctx context.Context, path string, params url.Values) ([]models.Order, string, error) {
+	var res struct {
+		List           []models.BybitOrderResponse `json:"list"`
+		NextPageCursor string                      `json:"nextPageCursor"`
+	}
+	if err := b.call(ctx, http.MethodGet, path, params, nil, &res, errs.ErrOrderNotFound); err != nil {
+		return nil, "", err
+	}
+
+	orders := make([]models.Order, 0, len(res.List))
+	for _, o := range res.List {
+		order, err := b.convertOrder(o)
+		if err != nil {
+			return nil, "", err
+		}
+		orders = append(orders, order)
+	}
+	return orders, res.NextPageCursor, nil
+}
+
+func (b *BybitExchange) CancelAllOrders(ctx context.Context, symbol models.Symbol, side string) ([]models.CancelResult, error) {
+	if side != "" {
+		return cancelAllOpenOrders(ctx, b, symbol, side)
+	}
+
+	payload := map[string]string{"category": "spot"}
+	if !symbol.IsZero() {
+		payload["symbol"] = bybitSymbol(symbol)
+	}
+	var res struct {
+		List []struct {
+			OrderID string `json:"orderId"`
+		} `json:"list"`
+	}
+	if err := b.call(ctx, http.MethodPost, "/v5/order/cancel-all", nil, payload, &res, errs.ErrSymbolNotFound); err != nil {
+		b.logger.Error("cancel all orders failed", zap.Error(err))
+		return nil, err
+	}
+
+	results := make([]models.CancelResult, 0, len(res.List))
+	for _, o := range res.List {
+		results = append(results, cancelResult(o.OrderID, symbol, nil))
+	}
+	return results, nil
+}
+
+func (b *BybitExchange) GetFills(ctx context.Context, symbol models.Symbol, since time.Time) ([]models.Fill, error) {
+	params := url.Values{
+		"category":  {"spot"},
+		"startTime": {strconv.FormatInt(since.UnixMilli(), 10)},
+	}
+	if !symbol.IsZero() {
+		params.Set("symbol", bybitSymbol(symbol))
+	}
+
+	var res struct {
+		List []models.BybitExecutionResponse `json:"list"`
+	}
+	if err := b.call(ctx, http.MethodGet, "/v5/execution/list", params, nil, &res, errs.ErrSymbolNotFound); err != nil {
+		b.logger.Error("list fills failed", zap.Error(err))
+		return nil, err
+	}
+
+	var p decimals
+	fills := make([]models.Fill, 0, len(res.List))
+	for _, e := range res.List {
+		fills = append(fills, models.Fill{
+			TradeID:   e.ExecID,
+			OrderID:   e.OrderID,
+			Symbol:    b.symbols.lookup(e.Symbol),
+			Side:      strings.ToLower(e.Side),
+			Price:     p.parse(e.ExecPrice),
+			Quantity:  p.parse(e.ExecQty),
+			Fee:       p.parse(e.ExecFee),
+			FeeAsset:  e.FeeCurrency,
+			Liquidity: liquidity(e.IsMaker),
+			Timestamp: parseMillis(e.ExecTime),
+		})
+	}
+	if p.err != nil {
+		b.logger.Error("parse fills failed", zap.Error(p.err))
+		return nil, p.err
+	}
+	return fills, nil
+}
+
+func (b *BybitExchange) GetBalance(ctx context.Context, asset string) (decimal.Decimal, error) {
+	params := url.Values{
+		"accountType": {"UNIFIED"},
+		"coin":        {strings.ToUpper(asset)},
+	}
+
+	var res struct {
+		List []struct {
+			Coin []struct {
+				Coin          string `json:"coin"`
+				WalletBalance string `json:"walletBalance"`
+				Locked        string `json:"locked"`
+			} `json:"coin"`
+		} `json:"list"`
+	}
+	if err := b.call(ctx, http.MethodGet, "/v5/account/wallet-balance", params, nil, &res, errs.ErrAssetNotFound); err != nil {
+		b.logger.Error("get balance failed", zap.String("asset", asset), zap.Error(err))
+		return decimal.Zero, err
+	}
+
+	for _, account := range res.List {
+		for _, c := range account.Coin {
+			if strings.EqualFold(c.Coin, asset) {
+				var p decimals
+				balance := p.parse(c.WalletBalance).Sub(p.parse(c.Locked))
+				if p.err != nil {
+					b.logger.Error("parse balance failed", zap.String("balance", c.WalletBalance), zap.Error(p.err))
+					return decimal.Zero, p.err
+				}
+				return balance, nil
+			}
+		}
+	}
+	return decimal.Zero, errs.New(errs.ErrAssetNotFound, "asset %s not found", asset)
+}
+
+func (b *BybitExchange) GetBalances(ctx context.Context) ([]models.Balance, error) {
+	params := url.Values{"accountType": {"UNIFIED"}}
+
+	var res struct {
+		List []struct {
+			Coin []struct {
+				Coin          string `json:"coin"`
+				WalletBalance string `json:"walletBalance"`
+				Locked        string `json:"locked"`
+			} `json:"coin"`
+		} `json:"list"`
+	}
+	if err := b.call(ctx, http.MethodGet, "/v5/account/wallet-balance", params, nil, &res, nil); err != nil {
+		b.logger.Error("get balances failed", zap.Error(err))
+		return nil, err
+	}
+
+	var p decimals
+	var balances []models.Balance
+	for _, account := range res.List {
+		for _, c := range account.Coin {
+			locked := p.parse(c.Locked)
+			balances = appendBalance(balances, c.Coin, p.parse(c.WalletBalance).Sub(locked), locked)
+		}
+	}
+	if p.err != nil {
+		b.logger.Error("parse balances failed", zap.Error(p.err))
+		return nil, p.err
+	}
+	return balances, nil
+}
+
+func (b *BybitExchange) GetMarkets(ctx context.Context) ([]models.Market, error) {
+	params := url.Values{"category": {"spot"}}
+
+	var res struct {
+		List []models.BybitInstrumentResponse `json:"list"`
+	}
+	if err := b.call(ctx, http.MethodGet, "/v5/market/instruments-info", params, nil, &res, errs.ErrExchangeUnavailable); err != nil {
+		b.logger.Error("get markets failed", zap.Error(err))
+		return nil, err
+	}
+
+	var p decimals
+	markets := make([]models.Market, 0, len(res.List))
+	for _, m := range res.List {
+		symbol := models.Symbol{Base: m.BaseCoin, Quote: m.QuoteCoin}
+		b.symbols.store(m.Symbol, symbol)
+		markets = append(markets, models.Market{
+			Symbol:      symbol,
+			Tradable:    m.Status == "Trading",
+			TickSize:    p.parse(m.PriceFilter.TickSize),
+			StepSize:    p.parse(m.LotSizeFilter.BasePrecision),
+			MinQuantity: p.parse(m.LotSizeFilter.MinOrderQty),
+			MaxQuantity: p.parse(m.LotSizeFilter.MaxOrderQty),
+			MinNotional: p.parse(m.LotSizeFilter.MinOrderAmt),
+		})
+	}
+	if p.err != nil {
+		b.logger.Error("parse markets failed", zap.Error(p.err))
+		return nil, p.err
+	}
+	return markets, nil
+}
+
+// call sends a request and decodes the result field of Bybit's
+// {"retCode", "retMsg", "result"} envelope into result.
+func (b *BybitExchange) call(ctx context.Context, method, path string, params url.Values, payload any, result any, notFound *errs.Kind) error {
+	endpoint := b.baseURL + path
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+	var body []byte
+	if payload != nil {
+		var err error
+		if body, err = json.Marshal(payload); err != nil {
+			return errs.Wrap(errs.ErrInternal, err)
+		}
+	}
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	respBody, status, err := b.client.Do(ctx, method, endpoint, body, headers, b.signer)
+	if err != nil || status < 200 || status >= 300 {
+		return httpError(path, status, respBody, err, notFound)
+	}
+
+	var envelope struct {
+		RetCode int             `json:"retCode"`
+		RetMsg  string          `json:"retMsg"`
+		Result  json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(respBody, &envelope); err != nil {
+		return errs.Wrap(errs.ErrInternal, err)
+	}
+	if envelope.RetCode != 0 {
+		return bybitError(path, envelope.RetCode, envelope.RetMsg, notFound)
+	}
+	if err := json.Unmarshal(envelope.Result, result); err != nil {
+		return errs.Wrap(errs.ErrInternal, err)
+	}
+	return nil
+}
+
+// convertOrder maps an order. Bybit leaves the average price empty until
+// the order fills.
+func (b *BybitExchange) convertOrder(o models.BybitOrderResponse) (models.Order, error) {
+	var p decimals
+	return models.Order{
+		OrderID:        o.OrderID,
+		Symbol:         b.symbols.lookup(o.Symbol),
+		Side:           strings.ToLower(o.Side),
+		Type:           strings.ToLower(o.OrderType),
+		Status:         bybitOrderStatus(o.OrderStatus),
+		Price:          p.parse(o.Price),
+		Quantity:       p.parse(o.Qty),
+		FilledQuantity: p.parse(o.CumExecQty),
+		AvgFillPrice:   p.optional(o.AvgPrice),
+		CreatedAt:      parseMillis(o.CreatedTime),
+		UpdatedAt:      parseMillis(o.UpdatedTime),
+	}, p.err
+}
+
+func bybitSymbol(symbol models.Symbol) string {
+	return symbol.Join("")
+}
+
+// bybitCase turns buy or LIMIT into Buy and Limit.
+func bybitCase(value string) string {
+	if value == "" {
+		return value
+	}
+	return strings.ToUpper(value[:1]) + strings.ToLower(value[1:])
+}
+
+func bybitOrderStatus(status string) models.OrderStatus {
+	switch status {
+	case "PartiallyFilled":
+		return models.OrderStatusPartiallyFilled
+	case "Filled":
+		return models.OrderStatusFilled
+	case "Cancelled", "PartiallyFilledCanceled", "Deactivated":
+		return models.OrderStatusCanceled
+	case "Rejected":
+		return models.OrderStatusRejected
+	}
+	return models.OrderStatusNew
+}
+
+func bybitError(op string, code int, msg string, notFound *errs.Kind) error {
+	kind := errs.ErrInvalidRequest
+	switch code {
+	case 10003, 10004, 10005, 10007, 33004:
+		kind = errs.ErrAuthFailed
+	case 10006, 10018:
+		kind = errs.ErrRateLimited
+	case 10016:
+		kind = errs.ErrExchangeUnavailable
+	case 170131, 110004, 110007:
+		kind = errs.ErrInsufficientFunds
+	case 170121, 110001, 170213:
+		kind = notFound
+	}
+	return errs.Wrap(kind, fmt.Errorf("bybit %s: retCode=%d, retMsg=%s", op, code, msg))
+}
@@ -1,27 +1,44 @@
 package exchange
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/shopspring/decimal"
+
+	"eyeOne/internal/errs"
+	"eyeOne/internal/httpclient"
 	"eyeOne/models"
 )
 
 type Exchange interface {
-	CreateOrder(ctx context.Context, symbol, side, orderType string, quantity, price float64) (string, error, int)
-	CancelOrder(ctx context.Context, symbol, orderID string) (error, int)
-	GetBalance(ctx context.Context, asset string) (float64, error, int)
-	GetOrderBook(ctx context.Context, symbol string) (models.OrderBook, error, int)
-}
-
-type OrderBook struct {
-	Asks []OrderBookEntry
-	Bids []OrderBookEntry
+	CreateOrder(ctx context.Context, symbol models.Symbol, side, orderType string, quantity, price decimal.Decimal) (string, error)
+	CancelOrder(ctx context.Context, symbol models.Symbol, orderID string) error
+	GetOrder(ctx context.Context, symbol models.Symbol, orderID string) (models.Order, error)
+	ListOpenOrders(ctx context.Context, symbol models.Symbol) ([]models.Order, error)
+	ListOrderHistory(ctx context.Context, query models.OrderHistoryQuery) ([]models.Order, error)
+	CancelAllOrders(ctx context.Context, symbol models.Symbol, side string) ([]models.CancelResult, error)
+	GetFills(ctx context.Context, symbol models.Symbol, since time.Time) ([]models.Fill, error)
+	GetBalance(ctx context.Context, asset string) (decimal.Decimal, error)
+	GetBalances(ctx context.Context) ([]models.Balance, error)
+	GetOrderBook(ctx context.Context, symbol models.Symbol, limit int) (models.OrderBook, error)
+	GetTicker(ctx context.Context, symbol models.Symbol) (models.Ticker, error)
+	GetCandles(ctx context.Context, symbol models.Symbol, interval models.CandleInterval, from, to time.Time, limit int) ([]models.Candle, error)
+	GetRecentTrades(ctx context.Context, symbol models.Symbol, limit int) ([]models.Trade, error)
+	GetMarkets(ctx context.Context) ([]models.Market, error)
 }
 
-type OrderBookEntry struct {
-	Price    float64
-	Quantity float64
+// Limited is implemented by adapters that answer some operations with
+// ErrNotSupported. Unsupported names them as GET /exchanges reports them,
+// e.g. "orderHistory".
+type Limited interface {
+	Unsupported() []string
 }
 
 type ExchangeType string
@@ -30,8 +47,31 @@ const (
 	Binance ExchangeType = "binance"
 	KuCoin  ExchangeType = "kucoin"
 	Bitpin  ExchangeType = "bitpin"
+	Wallex  ExchangeType = "wallex"
+	Nobitex ExchangeType = "nobitex"
+	OKX     ExchangeType = "okx"
+	Bybit   ExchangeType = "bybit"
+	Kraken  ExchangeType = "kraken"
+	Paper   ExchangeType = "paper"
 )
 
+// AccountType names a named account on an exchange, e.g. binance:mm.
+func AccountType(venue ExchangeType, account string) ExchangeType {
+	return ExchangeType(string(venue) + ":" + account)
+}
+
+// Venue strips the account name, so binance:mm becomes binance.
+func (t ExchangeType) Venue() ExchangeType {
+	venue, _, _ := strings.Cut(string(t), ":")
+	return ExchangeType(venue)
+}
+
+// Account returns the account name, or "" for an exchange's default keys.
+func (t ExchangeType) Account() string {
+	_, account, _ := strings.Cut(string(t), ":")
+	return account
+}
+
 var registry = make(map[ExchangeType]Exchange)
 
 func RegisterExchange(name ExchangeType, ex Exchange) {
@@ -41,7 +81,244 @@ func RegisterExchange(name ExchangeType, ex Exchange) {
 func GetExchange(name ExchangeType) (Exchange, error) {
 	ex, ok := registry[name]
 	if !ok {
-		return nil, fmt.Errorf("exchange not registered: %s", name)
+		return nil, errs.New(errs.ErrExchangeNotFound, "exchange not registered: %s", name)
 	}
 	return ex, nil
 }
+
+func parseDecimal(s string) (decimal.Decimal, error) {
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		return decimal.Zero, errs.New(errs.ErrInternal, "invalid number %q: %w", s, err)
+	}
+	return d, nil
+}
+
+// decimals parses the numeric fields of a venue response. It keeps the
+// first failure so a converter can read every field and check err once.
+type decimals struct {
+	err error
+}
+
+func (p *decimals) parse(s string) decimal.Decimal {
+	d, err := parseDecimal(s)
+	if err != nil && p.err == nil {
+		p.err = err
+	}
+	return d
+}
+
+// optional reads a field that venues leave empty when it does not apply,
+// such as the average price of an unfilled order, as zero.
+func (p *decimals) optional(s string) decimal.Decimal {
+	if s == "" {
+		return decimal.Zero
+	}
+	return p.parse(s)
+}
+
+// convertEntries reads [price, quantity, ...] book levels; fields past the
+// second are ignored.
+func convertEntries(p *decimals, entries [][]string) []models.OrderBookEntry {
+	result := make([]models.OrderBookEntry, 0, len(entries))
+	for _, entry := range entries {
+		if len(entry) < 2 {
+			continue
+		}
+		result = append(result, models.OrderBookEntry{
+			Price:    p.parse(entry[0]),
+			Quantity: p.parse(entry[1]),
+		})
+	}
+	return result
+}
+
+func avgPrice(quote, base decimal.Decimal) decimal.Decimal {
+	if base.IsZero() {
+		return decimal.Zero
+	}
+	return quote.Div(base)
+}
+
+func liquidity(isMaker bool) models.Liquidity {
+	if isMaker {
+		return models.LiquidityMaker
+	}
+	return models.LiquidityTaker
+}
+
+// appendBalance adds an asset to a balance snapshot unless it is empty.
+func appendBalance(balances []models.Balance, asset string, free, locked decimal.Decimal) []models.Balance {
+	total := free.Add(locked)
+	if total.IsZero() {
+		return balances
+	}
+	return append(balances, models.Balance{
+		Asset:  strings.ToUpper(asset),
+		Free:   free,
+		Locked: locked,
+		Total:  total,
+	})
+}
+
+// nativeDepth picks the smallest of a venue's fixed book depths, in
+// ascending order, that covers limit, or the largest when none does.
+func nativeDepth(limit int, depths ...int) int {
+	for _, depth := range depths {
+		if depth >= limit {
+			return depth
+		}
+	}
+	return depths[len(depths)-1]
+}
+
+// maxCandlePages bounds how many requests one GetCandles call may make.
+const maxCandlePages = 20
+
+// pageCandles fetches up to limit candles opening in [from, to), oldest
+// first, in windows of at most pageSize candles for venues that cap a
+// single response. fetch may return candles in any order and outside its
+// window; they are sorted, trimmed and deduplicated here.
+func pageCandles(interval models.CandleInterval, from, to time.Time, limit, pageSize int, fetch func(start, end time.Time, size int) ([]models.Candle, error)) ([]models.Candle, error) {
+	var candles []models.Candle
+	start := from
+	for page := 0; page < maxCandlePages && start.Before(to) && len(candles) < limit; page++ {
+		size := min(pageSize, limit-len(candles))
+		end := start.Add(interval.Duration() * time.Duration(size))
+		if end.After(to) {
+			end = to
+		}
+
+		batch, err := fetch(start, end, size)
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(batch, func(i, j int) bool {
+			return batch[i].OpenTime.Before(batch[j].OpenTime)
+		})
+		for _, c := range batch {
+			if c.OpenTime.Before(start) || !c.OpenTime.Before(end) || len(candles) == limit {
+				continue
+			}
+			if n := len(candles); n > 0 && !c.OpenTime.After(candles[n-1].OpenTime) {
+				continue
+			}
+			candles = append(candles, c)
+		}
+		start = end
+	}
+	return candles, nil
+}
+
+// rowCandles converts rows of millisecond open time, open, high, low, close
+// and base volume, the layout OKX and Bybit share.
+func rowCandles(rows [][]string) ([]models.Candle, error) {
+	var p decimals
+	candles := make([]models.Candle, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 6 {
+			continue
+		}
+		ts, err := strconv.ParseInt(row[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		candles = append(candles, models.Candle{
+			OpenTime: time.UnixMilli(ts),
+			Open:     p.parse(row[1]),
+			High:     p.parse(row[2]),
+			Low:      p.parse(row[3]),
+			Close:    p.parse(row[4]),
+			Volume:   p.parse(row[5]),
+		})
+	}
+	return candles, p.err
+}
+
+// udfCandles converts a TradingView UDF history response, the format the
+// Iranian venues use for candles.
+func udfCandles(res models.UDFHistoryResponse) []models.Candle {
+	candles := make([]models.Candle, 0, len(res.Time))
+	for i, ts := range res.Time {
+		if i >= len(res.Open) || i >= len(res.High) || i >= len(res.Low) || i >= len(res.Close) {
+			break
+		}
+		c := models.Candle{
+			OpenTime: time.Unix(ts, 0),
+			Open:     res.Open[i],
+			High:     res.High[i],
+			Low:      res.Low[i],
+			Close:    res.Close[i],
+		}
+		if i < len(res.Volume) {
+			c.Volume = res.Volume[i]
+		}
+		candles = append(candles, c)
+	}
+	return candles
+}
+
+// getUDFHistory requests one window from a UDF history endpoint. A
+// "no_data" answer is an empty window, not an error.
+func getUDFHistory(ctx context.Context, client *httpclient.Client, endpoint string, headers map[string]string) ([]models.Candle, error) {
+	body, status, err := client.Get(ctx, endpoint, headers)
+	if err != nil || status < 200 || status >= 300 {
+		return nil, httpError("candles", status, body, err, errs.ErrSymbolNotFound)
+	}
+
+	var res models.UDFHistoryResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, errs.Wrap(errs.ErrInternal, err)
+	}
+	switch res.Status {
+	case "ok":
+		return udfCandles(res), nil
+	case "no_data":
+		return nil, nil
+	}
+	return nil, errs.New(errs.ErrInvalidRequest, "candles failed: %s", res.Error)
+}
+
+// udfResolution names an interval the way UDF endpoints expect.
+func udfResolution(interval models.CandleInterval) string {
+	if interval == models.CandleInterval1d {
+		return "1D"
+	}
+	return strconv.Itoa(interval.Minutes())
+}
+
+var hundred = decimal.NewFromInt(100)
+
+// changeFromOpen fills the 24h change of a ticker from the price it
+// opened the window at.
+func changeFromOpen(t *models.Ticker, open decimal.Decimal) {
+	if open.IsZero() {
+		return
+	}
+	t.PriceChange = t.LastPrice.Sub(open)
+	t.PriceChangePercent = t.PriceChange.Div(open).Mul(hundred)
+}
+
+// changeFromPercent fills the 24h change of a ticker for venues that only
+// publish it as a percentage.
+func changeFromPercent(t *models.Ticker, percent decimal.Decimal) {
+	t.PriceChangePercent = percent
+	if factor := hundred.Add(percent); !factor.IsZero() {
+		t.PriceChange = t.LastPrice.Sub(t.LastPrice.Mul(hundred).Div(factor))
+	}
+}
+
+// httpError classifies a failed REST call by its HTTP status. Venues that
+// reject orders for lack of funds with a plain 400 are recognised by body.
+func httpError(op string, status int, body []byte, err error, notFound *errs.Kind) error {
+	kind := errs.FromHTTPStatus(status, notFound)
+	if kind == errs.ErrInvalidRequest && bytes.Contains(bytes.ToLower(body), []byte("insufficient")) {
+		kind = errs.ErrInsufficientFunds
+	}
+	if err == nil {
+		err = fmt.Errorf("%s failed with status %d", op, status)
+	} else {
+		err = fmt.Errorf("%s: %w", op, err)
+	}
+	return errs.Wrap(kind, err)
+}
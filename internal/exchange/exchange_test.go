@@ -0,0 +1,39 @@
+package exchange
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"eyeOne/config"
+	"eyeOne/internal/httpclient"
+	"eyeOne/models"
+)
+
+var btcUSDT = models.Symbol{Base: "BTC", Quote: "USDT"}
+
+// newTestAdapter builds an adapter with newAdapter and points it at a local
+// server running handler. configure returns the adapter's configuration for
+// the server's base URL.
+func newTestAdapter[T any](t *testing.T, handler http.HandlerFunc, newAdapter func(*httpclient.Client, *zap.Logger, *config.Config) (T, error), configure func(baseURL string) *config.Config) T {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	logger := zap.NewNop()
+	adapter, err := newAdapter(httpclient.New(logger), logger, configure(server.URL+"/"))
+	if err != nil {
+		t.Fatalf("new adapter: %v", err)
+	}
+	return adapter
+}
+
+func assertDecimal(t *testing.T, name string, got decimal.Decimal, want string) {
+	t.Helper()
+	if !got.Equal(decimal.RequireFromString(want)) {
+		t.Errorf("%s = %s, want %s", name, got, want)
+	}
+}
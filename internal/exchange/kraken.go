@@ -0,0 +1,727 @@
+package exchange
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"eyeOne/config"
+	"eyeOne/internal/errs"
+	"eyeOne/internal/httpclient"
+	"eyeOne/models"
+)
+
+const (
+	krakenBookDepth       = 500
+	krakenMaxHistoryPages = 10
+	krakenMaxCandles      = 720
+	krakenMaxTrades       = 1000
+)
+
+// krakenAssets maps Kraken's legacy X/Z prefixed codes to canonical names.
+var krakenAssets = map[string]string{
+	"XXBT": "BTC", "XBT": "BTC",
+	"XXDG": "DOGE", "XDG": "DOGE",
+	"XETH": "ETH", "XETC": "ETC", "XLTC": "LTC", "XXRP": "XRP",
+	"XXLM": "XLM", "XXMR": "XMR", "XZEC": "ZEC", "XREP": "REP", "XMLN": "MLN",
+	"ZUSD": "USD", "ZEUR": "EUR", "ZGBP": "GBP", "ZCAD": "CAD",
+	"ZJPY": "JPY", "ZAUD": "AUD", "ZCHF": "CHF",
+}
+
+// krakenQuotes are tried when splitting altnames; the generic list would
+// read XBTUSD as XB/TUSD.
+var krakenQuotes = sortedByLength([]string{
+	"USDT", "USDC", "USD", "EUR", "GBP", "CAD", "JPY", "AUD", "CHF", "DAI", "XBT", "ETH",
+})
+
+type KrakenExchange struct {
+	baseURL string
+	client  *httpclient.Client
+	signer  httpclient.Signer
+	nonce   *krakenNonce
+	symbols *symbolIndex
+	logger  *zap.Logger
+}
+
+func NewKrakenExchange(client *httpclient.Client, logger *zap.Logger, cfg *config.Config) (*KrakenExchange, error) {
+	if !cfg.KrakenEnv.IsProduction() {
+		return nil, fmt.Errorf("kraken has no spot %s environment", cfg.KrakenEnv)
+	}
+	k := &KrakenExchange{
+		baseURL: "https://api.kraken.com",
+		client:  client,
+		nonce:   &krakenNonce{},
+		symbols: newSymbolIndex(),
+		logger:  logger,
+	}
+	if cfg.HasKrakenCredentials() {
+		secret, err := base64.StdEncoding.DecodeString(cfg.KrakenSecretKey)
+		if err != nil {
+			return nil, fmt.Errorf("kraken secret key is not valid base64: %w", err)
+		}
+		k.signer = newKrakenSigner(cfg.KrakenAPIKey, secret)
+	}
+	return k, nil
+}
+
+// krakenNonce hands out strictly increasing nonces to concurrent callers.
+type krakenNonce struct {
+	last atomic.Int64
+}
+
+func (n *krakenNonce) next() int64 {
+	for {
+		last := n.last.Load()
+		next := time.Now().UnixMicro()
+		if next <= last {
+			next = last + 1
+		}
+		if n.last.CompareAndSwap(last, next) {
+			return next
+		}
+	}
+}
+
+// newKrakenSigner signs with HMAC-SHA512 over the URI path followed by
+// SHA256(nonce + POST data), keyed with the decoded secret.
+func newKrakenSigner(apiKey string, secret []byte) httpclient.Signer {
+	return httpclient.SignerFunc(func(req *http.Request, body []byte) error {
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return err
+		}
+		digest := sha256.Sum256([]byte(form.Get("nonce") + string(body)))
+
+		mac := hmac.New(sha512.New, secret)
+		mac.Write([]byte(req.URL.Path))
+		mac.Write(digest[:])
+		req.Header.Set("API-Key", apiKey)
+		req.Header.Set("API-Sign", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+		return nil
+	})
+}
+
+func (k *KrakenExchange) GetOrderBook(ctx context.Context, symbol models.Symbol, limit int) (models.OrderBook, error) {
+	depth := krakenBookDepth
+	if limit > 0 {
+		depth = min(limit, krakenBookDepth)
+	}
+	params := url.Values{
+		"pair":  {krakenSymbol(symbol)},
+		"count": {strconv.Itoa(depth)},
+	}
+	var books map[string]struct {
+		Asks [][]any `json:"asks"`
+		Bids [][]any `json:"bids"`
+	}
+	if err := k.public(ctx, "Depth", params, &books, errs.ErrSymbolNotFound); err != nil {
+		k.logger.Error("get order book failed", zap.String("symbol", symbol.String()), zap.Error(err))
+		return models.OrderBook{}, err
+	}
+
+	// The result is keyed by Kraken's own pair name, which may differ from
+	// the one requested, so take whatever single entry came back.
+	for _, book := range books {
+		var p decimals
+		result := models.OrderBook{
+			Bids: krakenEntries(&p, book.Bids),
+			Asks: krakenEntries(&p, book.Asks),
+		}
+		if p.err != nil {
+			k.logger.Error("parse order book failed", zap.String("symbol", symbol.String()), zap.Error(p.err))
+			return models.OrderBook{}, p.err
+		}
+		return result, nil
+	}
+	return models.OrderBook{}, errs.New(errs.ErrSymbolNotFound, "no order book for %s", symbol)
+}
+
+// GetTicker maps Kraken's array-valued ticker. Index 1 of the volume, VWAP,
+// high and low arrays covers the last 24 hours; the open is today's.
+func (k *KrakenExchange) GetTicker(ctx context.Context, symbol models.Symbol) (models.Ticker, error) {
+	params := url.Values{"pair": {krakenSymbol(symbol)}}
+	var tickers map[string]struct {
+		Ask    []string `json:"a"`
+		Bid    []string `json:"b"`
+		Last   []string `json:"c"`
+		Volume []string `json:"v"`
+		VWAP   []string `json:"p"`
+		Low    []string `json:"l"`
+		High   []string `json:"h"`
+		Open   string   `json:"o"`
+	}
+	if err := k.public(ctx, "Ticker", params, &tickers, errs.ErrSymbolNotFound); err != nil {
+		k.logger.Error("get ticker failed", zap.String("symbol", symbol.String()), zap.Error(err))
+		return models.Ticker{}, err
+	}
+
+	var p decimals
+	at := func(values []string, i int) decimal.Decimal {
+		if i < len(values) {
+			return p.parse(values[i])
+		}
+		return decimal.Zero
+	}
+	for _, t := range tickers {
+		volume := at(t.Volume, 1)
+		ticker := models.Ticker{
+			Symbol:      symbol,
+			LastPrice:   at(t.Last, 0),
+			BidPrice:    at(t.Bid, 0),
+			AskPrice:    at(t.Ask, 0),
+			HighPrice:   at(t.High, 1),
+			LowPrice:    at(t.Low, 1),
+			Volume:      volume,
+			QuoteVolume: volume.Mul(at(t.VWAP, 1)),
+			Timestamp:   time.Now(),
+		}
+		changeFromOpen(&ticker, p.parse(t.Open))
+		if p.err != nil {
+			k.logger.Error("parse ticker failed", zap.String("symbol", symbol.String()), zap.Error(p.err))
+			return models.Ticker{}, p.err
+		}
+		return ticker, nil
+	}
+	return models.Ticker{}, errs.New(errs.ErrSymbolNotFound, "no ticker for %s", symbol)
+}
+
+// GetCandles reads Kraken's OHLC, which only ever serves the most recent
+// 720 candles of an interval; older windows come back empty.
+func (k *KrakenExchange) GetCandles(ctx context.Context, symbol models.Symbol, interval models.CandleInterval, from, to time.Time, limit int) ([]models.Candle, error) {
+	return pageCandles(interval, from, to, limit, krakenMaxCandles, func(start, end time.Time, size int) ([]models.Candle, error) {
+		params := url.Values{
+			"pair":     {krakenSymbol(symbol)},
+			"interval": {strconv.Itoa(interval.Minutes())},
+			"since":    {strconv.FormatInt(start.Unix()-1, 10)},
+		}
+		var res map[string]json.RawMessage
+		if err := k.public(ctx, "OHLC", params, &res, errs.ErrSymbolNotFound); err != nil {
+			k.logger.Error("get candles failed", zap.String("symbol", symbol.String()), zap.Error(err))
+			return nil, err
+		}
+
+		// Rows are time, open, high, low, close, vwap, volume and count,
+		// keyed by pair next to a "last" cursor.
+		for key, raw := range res {
+			if key == "last" {
+				continue
+			}
+			var rows [][]any
+			if err := json.Unmarshal(raw, &rows); err != nil {
+				return nil, errs.Wrap(errs.ErrInternal, err)
+			}
+			var p decimals
+			candles := make([]models.Candle, 0, len(rows))
+			for _, row := range rows {
+				if len(row) < 7 {
+					continue
+				}
+				ts, _ := row[0].(float64)
+				field := func(i int) decimal.Decimal {
+					s, _ := row[i].(string)
+					return p.parse(s)
+				}
+				candles = append(candles, models.Candle{
+					OpenTime: time.Unix(int64(ts), 0),
+					Open:     field(1),
+					High:     field(2),
+					Low:      field(3),
+					Close:    field(4),
+					Volume:   field(6),
+				})
+			}
+			if p.err != nil {
+				k.logger.Error("parse candles failed", zap.String("symbol", symbol.String()), zap.Error(p.err))
+				return nil, p.err
+			}
+			return candles, nil
+		}
+		return nil, nil
+	})
+}
+
+func (k *KrakenExchange) GetRecentTrades(ctx context.Context, symbol models.Symbol, limit int) ([]models.Trade, error) {
+	params := url.Values{
+		"pair":  {krakenSymbol(symbol)},
+		"count": {strconv.Itoa(min(limit, krakenMaxTrades))},
+	}
+	var res map[string]json.RawMessage
+	if err := k.public(ctx, "Trades", params, &res, errs.ErrSymbolNotFound); err != nil {
+		k.logger.Error("get recent trades failed", zap.String("symbol", symbol.String()), zap.Error(err))
+		return nil, err
+	}
+
+	// Rows are price, volume, time, "b" or "s", order type, misc and trade
+	// ID, keyed by pair next to a "last" cursor.
+	for key, raw := range res {
+		if key == "last" {
+			continue
+		}
+		var rows [][]any
+		if err := json.Unmarshal(raw, &rows); err != nil {
+			return nil, errs.Wrap(errs.ErrInternal, err)
+		}
+		var p decimals
+		trades := make([]models.Trade, 0, len(rows))
+		for _, row := range rows {
+			if len(row) < 4 {
+				continue
+			}
+			price, _ := row[0].(string)
+			volume, _ := row[1].(string)
+			ts, _ := row[2].(float64)
+			side := "buy"
+			if s, _ := row[3].(string); s == "s" {
+				side = "sell"
+			}
+			trade := models.Trade{
+				Price:     p.parse(price),
+				Quantity:  p.parse(volume),
+				Side:      side,
+				Timestamp: time.UnixMilli(int64(ts * 1000)),
+			}
+			if len(row) > 6 {
+				if id, ok := row[6].(float64); ok {
+					trade.TradeID = strconv.FormatInt(int64(id), 10)
+				}
+			}
+			trades = append(trades, trade)
+		}
+		if p.err != nil {
+			k.logger.Error("parse recent trades failed", zap.String("symbol", symbol.String()), zap.Error(p.err))
+			return nil, p.err
+		}
+		return trades, nil
+	}
+	return nil, errs.New(errs.ErrSymbolNotFound, "no trades for %s", symbol)
+}
+
+func (k *KrakenExchange) CreateOrder(ctx context.Context, symbol models.Symbol, side, orderType string, quantity, price decimal.Decimal) (string, error) {
+	params := url.Values{
+		"pair":      {krakenSymbol(symbol)},
+		"type":      {strings.ToLower(side)},
+		"ordertype": {strings.ToLower(orderType)},
+		"volume":    {quantity.String()},
+	}
+	if !strings.EqualFold(orderType, "market") {
+		params.Set("price", price.String())
+	}
+
+	var res struct {
+		TxID []string `json:"txid"`
+	}
+	if err := k.private(ctx, "AddOrder", params, &res, errs.ErrSymbolNotFound); err != nil {
+		k.logger.Error("order creation failed", zap.String("symbol", symbol.String()), zap.Error(err))
+		return "", err
+	}
+	if len(res.TxID) == 0 {
+		return "", errs.New(errs.ErrInternal, "kraken returned no order ID")
+	}
+	k.logger.Info("Order created", zap.String("symbol", symbol.String()), zap.String("orderId", res.TxID[0]))
+	return res.TxID[0], nil
+}
+
+func (k *KrakenExchange) CancelOrder(ctx context.Context, symbol models.Symbol, orderID string) error {
+	params := url.Values{"txid": {orderID}}
+	var res struct{}
+	if err := k.private(ctx, "CancelOrder", params, &res, errs.ErrOrderNotFound); err != nil {
+		k.logger.Error("cancel order failed", zap.String("orderId", orderID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (k *KrakenExchange) GetOrder(ctx context.Context, symbol models.Symbol, orderID string) (models.Order, error) {
+	params := url.Values{"txid": {orderID}}
+	var res map[string]models.KrakenOrderResponse
+	if err := k.private(ctx, "QueryOrders", params, &res, errs.ErrOrderNotFound); err != nil {
+		k.logger.Error("get order failed", zap.String("orderId", orderID), zap.Error(err))
+		return models.Order{}, err
+	}
+	order, ok := res[orderID]
+	if !ok {
+		return models.Order{}, errs.New(errs.ErrOrderNotFound, "order %s not found", orderID)
+	}
+	result, err := k.convertOrder(orderID, order)
+	if err != nil {
+		k.logger.Error("parse order failed", zap.String("orderId", orderID), zap.Error(err))
+		return models.Order{}, err
+	}
+	return result, nil
+}
+
+func (k *KrakenExchange) ListOpenOrders(ctx context.Context, symbol models.Symbol) ([]models.Order, error) {
+	var res struct {
+		Open map[string]models.KrakenOrderResponse `json:"open"`
+	}
+	if err := k.private(ctx, "OpenOrders", url.Values{}, &res, errs.ErrSymbolNotFound); err != nil {
+		k.logger.Error("list open orders failed", zap.Error(err))
+		return nil, err
+	}
+	orders, err := k.convertOrders(res.Open, symbol)
+	if err != nil {
+		k.logger.Error("parse open orders failed", zap.Error(err))
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (k *KrakenExchange) ListOrderHistory(ctx context.Context, query models.OrderHistoryQuery) ([]models.Order, error) {
+	params := url.Values{}
+	if !query.StartTime.IsZero() {
+		params.Set("start", strconv.FormatInt(query.StartTime.Unix(), 10))
+	}
+	if !query.EndTime.IsZero() {
+		params.Set("end", strconv.FormatInt(query.EndTime.Unix(), 10))
+	}
+
+	// ClosedOrders returns 50 orders per call and cannot filter by pair.
+	want := query.Page * query.Limit
+	var result []models.Order
+	for page, offset := 0, 0; page < krakenMaxHistoryPages && len(result) < want; page++ {
+		params.Set("ofs", strconv.Itoa(offset))
+		var res struct {
+			Closed map[string]models.KrakenOrderResponse `json:"closed"`
+			Count  int                                   `json:"count"`
+		}
+		if err := k.private(ctx, "ClosedOrders", params, &res, errs.ErrSymbolNotFound); err != nil {
+			k.logger.Error("list order history failed", zap.Error(err))
+			return nil, err
+		}
+		orders, err := k.convertOrders(res.Closed, query.Symbol)
+		if err != nil {
+			k.logger.Error("parse order history failed", zap.Error(err))
+			return nil, err
+		}
+		result = append(result, orders...)
+		offset += len(res.Closed)
+		if len(res.Closed) == 0 || offset >= res.Count {
+			break
+		}
+	}
+	return pageOrders(closedOrders(result), query.Page, query.Limit), nil
+}
+
+func (k *KrakenExchange) CancelAllOrders(ctx context.Context, symbol models.Symbol, side string) ([]models.CancelResult, error) {
+	return cancelAllOpenOrders(ctx, k, symbol, side)
+}
+
+func (k *KrakenExchange) GetFills(ctx context.Context, symbol models.Symbol, since time.Time) ([]models.Fill, error) {
+	params := url.Values{"start": {strconv.FormatInt(since.Unix(), 10)}}
+	var res struct {
+		Trades map[string]models.KrakenTradeResponse `json:"trades"`
+	}
+	if err := k.private(ctx, "TradesHistory", params, &res, errs.ErrSymbolNotFound); err != nil {
+		k.logger.Error("list fills failed", zap.Error(err))
+		return nil, err
+	}
+
+	var p decimals
+	fills := make([]models.Fill, 0, len(res.Trades))
+	for id, t := range res.Trades {
+		sym := k.lookupSymbol(t.Pair)
+		if !symbol.IsZero() && sym != symbol {
+			continue
+		}
+		fills = append(fills, models.Fill{
+			TradeID:  id,
+			OrderID:  t.OrderTxID,
+			Symbol:   sym,
+			Side:     strings.ToLower(t.Type),
+			Price:    p.parse(t.Price),
+			Quantity: p.parse(t.Vol),
+			Fee:      p.parse(t.Fee),
+			// Kraken charges fees in the quote currency by default.
+			FeeAsset:  sym.Quote,
+			Liquidity: liquidity(t.Maker),
+			Timestamp: krakenTime(t.Time),
+		})
+	}
+	if p.err != nil {
+		k.logger.Error("parse fills failed", zap.Error(p.err))
+		return nil, p.err
+	}
+	return fills, nil
+}
+
+func (k *KrakenExchange) GetBalance(ctx context.Context, asset string) (decimal.Decimal, error) {
+	var res map[string]struct {
+		Balance   string `json:"balance"`
+		HoldTrade string `json:"hold_trade"`
+	}
+	if err := k.private(ctx, "BalanceEx", url.Values{}, &res, errs.ErrAssetNotFound); err != nil {
+		k.logger.Error("get balance failed", zap.String("asset", asset), zap.Error(err))
+		return decimal.Zero, err
+	}
+
+	for code, b := range res {
+		if !strings.EqualFold(krakenAsset(code), asset) {
+			continue
+		}
+		// hold_trade is left out for assets with nothing on hold.
+		var p decimals
+		balance := p.parse(b.Balance).Sub(p.optional(b.HoldTrade))
+		if p.err != nil {
+			k.logger.Error("parse balance failed", zap.String("balance", b.Balance), zap.Error(p.err))
+			return decimal.Zero, p.err
+		}
+		return balance, nil
+	}
+	return decimal.Zero, errs.New(errs.ErrAssetNotFound, "asset %s not found", asset)
+}
+
+func (k *KrakenExchange) GetBalances(ctx context.Context) ([]models.Balance, error) {
+	var res map[string]struct {
+		Balance   string `json:"balance"`
+		HoldTrade string `json:"hold_trade"`
+	}
+	if err := k.private(ctx, "BalanceEx", url.Values{}, &res, nil); err != nil {
+		k.logger.Error("get balances failed", zap.Error(err))
+		return nil, err
+	}
+
+	var p decimals
+	var balances []models.Balance
+	for code, b := range res {
+		hold := p.optional(b.HoldTrade)
+		balances = appendBalance(balances, krakenAsset(code), p.parse(b.Balance).Sub(hold), hold)
+	}
+	if p.err != nil {
+		k.logger.Error("parse balances failed", zap.Error(p.err))
+		return nil, p.err
+	}
+	return balances, nil
+}
+
+func (k *KrakenExchange) GetMarkets(ctx context.Context) ([]models.Market, error) {
+	var res map[string]models.KrakenAssetPairResponse
+	if err := k.public(ctx, "AssetPairs", url.Values{}, &res, errs.ErrExchangeUnavailable); err != nil {
+		k.logger.Error("get markets failed", zap.Error(err))
+		return nil, err
+	}
+
+	markets := make([]models.Market, 0, len(res))
+	var d decimals
+	for name, p := range res {
+		symbol := models.Symbol{Base: krakenAsset(p.Base), Quote: krakenAsset(p.Quote)}
+		// Orders report the altname, trades the full pair name.
+		k.symbols.store(name, symbol)
+		k.symbols.store(p.Altname, symbol)
+		markets = append(markets, models.Market{
+			Symbol:      symbol,
+			Tradable:    p.Status == "online",
+			TickSize:    d.parse(p.TickSize),
+			StepSize:    models.PrecisionStep(p.LotDec),
+			MinQuantity: d.parse(p.OrderMin),
+			MinNotional: d.optional(p.CostMin),
+		})
+	}
+	if d.err != nil {
+		k.logger.Error("parse markets failed", zap.Error(d.err))
+		return nil, d.err
+	}
+	return markets, nil
+}
+
+func (k *KrakenExchange) public(ctx context.Context, method string, params url.Values, result any, notFound *errs.Kind) error {
+	endpoint := fmt.Sprintf("%s/0/public/%s", k.baseURL, method)
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+	body, status, err := k.client.Do(ctx, http.MethodGet, endpoint, nil, nil, nil)
+	return krakenResult(method, body, status, err, result, notFound)
+}
+
+// private signs a call with a fresh nonce. Requests may still reach Kraken
+// out of nonce order under load, so one rejected for its nonce is retried.
+func (k *KrakenExchange) private(ctx context.Context, method string, params url.Values, result any, notFound *errs.Kind) error {
+	endpoint := fmt.Sprintf("%s/0/private/%s", k.baseURL, method)
+	headers := map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	}
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		params.Set("nonce", strconv.FormatInt(k.nonce.next(), 10))
+		body, status, doErr := k.client.Do(ctx, http.MethodPost, endpoint, []byte(params.Encode()), headers, k.signer)
+		err = krakenResult(method, body, status, doErr, result, notFound)
+		if err == nil || !strings.Contains(err.Error(), "EAPI:Invalid nonce") {
+			return err
+		}
+		k.logger.Warn("kraken rejected nonce, retrying", zap.String("method", method))
+	}
+	return err
+}
+
+func (k *KrakenExchange) convertOrders(orders map[string]models.KrakenOrderResponse, symbol models.Symbol) ([]models.Order, error) {
+	result := make([]models.Order, 0, len(orders))
+	for id, o := range orders {
+		order, err := k.convertOrder(id, o)
+		if err != nil {
+			return nil, err
+		}
+		if !symbol.IsZero() && order.Symbol != symbol {
+			continue
+		}
+		result = append(result, order)
+	}
+	return result, nil
+}
+
+func (k *KrakenExchange) convertOrder(id string, o models.KrakenOrderResponse) (models.Order, error) {
+	var p decimals
+	filled := p.parse(o.VolExec)
+	createdAt := krakenTime(o.OpenTm)
+	updatedAt := krakenTime(o.CloseTm)
+	if updatedAt.IsZero() {
+		updatedAt = createdAt
+	}
+	return models.Order{
+		OrderID:        id,
+		Symbol:         k.lookupSymbol(o.Descr.Pair),
+		Side:           strings.ToLower(o.Descr.Type),
+		Type:           strings.ToLower(o.Descr.OrderType),
+		Status:         krakenOrderStatus(o.Status, filled),
+		Price:          p.parse(o.Descr.Price),
+		Quantity:       p.parse(o.Vol),
+		FilledQuantity: filled,
+		AvgFillPrice:   avgPrice(p.parse(o.Cost), filled),
+		CreatedAt:      createdAt,
+		UpdatedAt:      updatedAt,
+	}, p.err
+}
+
+// lookupSymbol resolves a Kraken pair name. Before the market list is
+// loaded it falls back to splitting XXBTZUSD or XBTUSD style names.
+func (k *KrakenExchange) lookupSymbol(pair string) models.Symbol {
+	if symbol, ok := k.symbols.find(pair); ok {
+		return symbol
+	}
+	pair = strings.ToUpper(pair)
+	if len(pair) == 8 && (pair[0] == 'X' || pair[0] == 'Z') && (pair[4] == 'X' || pair[4] == 'Z') {
+		return models.Symbol{Base: krakenAsset(pair[:4]), Quote: krakenAsset(pair[4:])}
+	}
+	for _, quote := range krakenQuotes {
+		if len(pair) > len(quote) && strings.HasSuffix(pair, quote) {
+			return models.Symbol{Base: krakenAsset(strings.TrimSuffix(pair, quote)), Quote: krakenAsset(quote)}
+		}
+	}
+	return models.Symbol{Base: krakenAsset(pair)}
+}
+
+// krakenSymbol builds the pair altname, e.g. XBTUSD for BTC/USD.
+func krakenSymbol(symbol models.Symbol) string {
+	return krakenCode(symbol.Base) + krakenCode(symbol.Quote)
+}
+
+func krakenCode(asset string) string {
+	switch strings.ToUpper(asset) {
+	case "BTC":
+		return "XBT"
+	case "DOGE":
+		return "XDG"
+	}
+	return strings.ToUpper(asset)
+}
+
+func krakenAsset(code string) string {
+	code = strings.ToUpper(code)
+	if asset, ok := krakenAssets[code]; ok {
+		return asset
+	}
+	return code
+}
+
+// krakenEntries reads [price, volume, timestamp] levels. Price and volume
+// are strings; anything else fails to parse.
+func krakenEntries(p *decimals, levels [][]any) []models.OrderBookEntry {
+	pairs := make([][]string, 0, len(levels))
+	for _, level := range levels {
+		if len(level) < 2 {
+			continue
+		}
+		price, _ := level[0].(string)
+		volume, _ := level[1].(string)
+		pairs = append(pairs, []string{price, volume})
+	}
+	return convertEntries(p, pairs)
+}
+
+func krakenOrderStatus(status string, filled decimal.Decimal) models.OrderStatus {
+	switch status {
+	case "closed":
+		return models.OrderStatusFilled
+	case "canceled", "expired":
+		return models.OrderStatusCanceled
+	}
+	if filled.IsPositive() {
+		return models.OrderStatusPartiallyFilled
+	}
+	return models.OrderStatusNew
+}
+
+func krakenTime(seconds float64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+	sec, frac := math.Modf(seconds)
+	return time.Unix(int64(sec), int64(frac*1e9))
+}
+
+// krakenResult decodes the {"error": [...], "result": ...} envelope.
+// Kraken answers most failures with HTTP 200 and a non-empty error list.
+func krakenResult(method string, body []byte, status int, err error, result any, notFound *errs.Kind) error {
+	var envelope struct {
+		Error  []string        `json:"error"`
+		Result json.RawMessage `json:"result"`
+	}
+	if jsonErr := json.Unmarshal(body, &envelope); jsonErr != nil {
+		if err != nil || status < 200 || status >= 300 {
+			return httpError(method, status, body, err, notFound)
+		}
+		return errs.Wrap(errs.ErrInternal, jsonErr)
+	}
+	if len(envelope.Error) > 0 {
+		return krakenError(method, envelope.Error, notFound)
+	}
+	if err != nil || status < 200 || status >= 300 {
+		return httpError(method, status, body, err, notFound)
+	}
+	if err := json.Unmarshal(envelope.Result, result); err != nil {
+		return errs.Wrap(errs.ErrInternal, err)
+	}
+	return nil
+}
+
+func krakenError(method string, messages []string, notFound *errs.Kind) error {
+	msg := strings.Join(messages, "; ")
+	kind := errs.ErrInvalidRequest
+	switch {
+	case strings.Contains(msg, "Rate limit"), strings.Contains(msg, "Too many requests"):
+		kind = errs.ErrRateLimited
+	case strings.Contains(msg, "Insufficient funds"):
+		kind = errs.ErrInsufficientFunds
+	case strings.Contains(msg, "Invalid key"), strings.Contains(msg, "Invalid signature"),
+		strings.Contains(msg, "Invalid nonce"), strings.Contains(msg, "Permission denied"):
+		kind = errs.ErrAuthFailed
+	case strings.Contains(msg, "Unknown asset pair"), strings.Contains(msg, "Unknown order"):
+		kind = notFound
+	case strings.HasPrefix(msg, "EService"):
+		kind = errs.ErrExchangeUnavailable
+	}
+	return errs.Wrap(kind, fmt.Errorf("kraken %s: %s", method, msg))
+}
@@ -8,154 +8,551 @@ import (
 	"time"
 
 	"github.com/Kucoin/kucoin-go-sdk"
+	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 
+	"eyeOne/internal/errs"
 	"eyeOne/models"
 	"eyeOne/pkg/logger"
 )
 
+const (
+	kucoinMaxPageSize    = 500
+	kucoinMaxCandles     = 1500
+	kucoinSandboxBaseURI = "https://openapi-sandbox.kucoin.com"
+)
+
 type KucoinExchange struct {
 	client *kucoin.ApiService
 	log    *zap.Logger
 }
 
-func NewKucoinExchange(apiKey, apiSecret, apiPassphrase string) (*KucoinExchange, error) {
+func NewKucoinExchange(apiKey, apiSecret, apiPassphrase string, env models.Environment) (*KucoinExchange, error) {
+	baseURI := kucoin.ProductionApiBaseURI
+	if !env.IsProduction() {
+		baseURI = kucoinSandboxBaseURI
+	}
 	client := kucoin.NewApiService(
+		kucoin.ApiBaseURIOption(baseURI),
 		kucoin.ApiKeyOption(apiKey),
 		kucoin.ApiSecretOption(apiSecret),
 		kucoin.ApiPassPhraseOption(apiPassphrase),
 	)
 
 	log := logger.GetLogger()
+	log.Info("Initialized KuCoin client", zap.String("environment", string(env)), zap.String("baseUrl", baseURI))
 	return &KucoinExchange{client: client, log: log}, nil
 }
 
-func (k *KucoinExchange) CreateOrder(ctx context.Context, symbol, side, orderType string, quantity, price float64) (string, error, int) {
-	clientOid := fmt.Sprintf("%s-%d", symbol, time.Now().UnixNano())
+func (k *KucoinExchange) CreateOrder(ctx context.Context, symbol models.Symbol, side, orderType string, quantity, price decimal.Decimal) (string, error) {
+	clientOid := fmt.Sprintf("%s-%d", kucoinSymbol(symbol), time.Now().UnixNano())
 
 	k.log.Info("Creating Kucoin order",
-		zap.String("symbol", symbol),
+		zap.String("symbol", symbol.String()),
 		zap.String("side", side),
 		zap.String("orderType", orderType),
-		zap.Float64("quantity", quantity),
-		zap.Float64("price", price),
+		zap.Stringer("quantity", quantity),
+		zap.Stringer("price", price),
 		zap.String("clientOid", clientOid),
 	)
 
-	priceStr := strconv.FormatFloat(price, 'f', -1, 64)
-	quantityStr := strconv.FormatFloat(quantity, 'f', -1, 64)
-
 	orderModel := &kucoin.CreateOrderModel{
-		ClientOid:   clientOid,
-		Side:        side,
-		Symbol:      symbol,
-		Type:        orderType,
-		Price:       priceStr,
-		Size:        quantityStr,
-		TimeInForce: "GTC",
+		ClientOid: clientOid,
+		Side:      strings.ToLower(side),
+		Symbol:    kucoinSymbol(symbol),
+		Type:      strings.ToLower(orderType),
+		Size:      quantity.String(),
 	}
-
-	order, err := k.client.CreateOrder(context.Background(), orderModel)
-	if err != nil {
-		k.log.Error("Failed to create order", zap.Error(err))
-		return "", fmt.Errorf("failed to create order: %v", err), 500
+	if !strings.EqualFold(orderType, "market") {
+		// KuCoin rejects market orders that carry a price or time in force.
+		orderModel.Price = price.String()
+		orderModel.TimeInForce = "GTC"
 	}
 
 	var orderResponse struct {
-		OrderOid string `json:"orderOid"`
+		OrderId string `json:"orderId"`
 	}
 
-	if err := order.ReadData(&orderResponse); err != nil {
-		k.log.Error("Failed to read order response", zap.Error(err))
-		return "", fmt.Errorf("failed to read order response: %v", err), 500
+	rsp, err := k.client.CreateOrder(ctx, orderModel)
+	if err := readKucoinData(rsp, err, &orderResponse); err != nil {
+		k.log.Error("Failed to create order", zap.Error(err))
+		return "", fmt.Errorf("failed to create order: %w", err)
 	}
 
-	k.log.Info("Order created successfully", zap.String("orderOid", orderResponse.OrderOid))
-	return orderResponse.OrderOid, nil, 201
+	k.log.Info("Order created successfully", zap.String("orderId", orderResponse.OrderId))
+	return orderResponse.OrderId, nil
 }
 
-func (k *KucoinExchange) CancelOrder(ctx context.Context, symbol, orderID string) (error, int) {
+func (k *KucoinExchange) CancelOrder(ctx context.Context, symbol models.Symbol, orderID string) error {
 	k.log.Info("Cancelling Kucoin order",
-		zap.String("symbol", symbol),
+		zap.String("symbol", symbol.String()),
 		zap.String("orderID", orderID),
 	)
 
-	_, err := k.client.CancelOrder(ctx, orderID)
-	if err != nil {
+	rsp, err := k.client.CancelOrder(ctx, orderID)
+	if err := readKucoinData(rsp, err, nil); err != nil {
 		k.log.Error("Failed to cancel order", zap.Error(err))
-		return fmt.Errorf("failed to cancel order: %w", err), 500
+		return fmt.Errorf("failed to cancel order: %w", err)
 	}
 
 	k.log.Info("Order cancelled successfully", zap.String("orderID", orderID))
-	return nil, 200
+	return nil
 }
 
-func (k *KucoinExchange) GetBalance(ctx context.Context, asset string) (float64, error, int) {
-	k.log.Info("Fetching Kucoin balance", zap.String("asset", asset))
+func (k *KucoinExchange) GetOrder(ctx context.Context, symbol models.Symbol, orderID string) (models.Order, error) {
+	k.log.Info("Fetching Kucoin order",
+		zap.String("symbol", symbol.String()),
+		zap.String("orderID", orderID),
+	)
 
-	rsp, err := k.client.Accounts(ctx, "", "")
+	var order kucoin.OrderModel
+	rsp, err := k.client.Order(ctx, orderID)
+	if err := readKucoinData(rsp, err, &order); err != nil {
+		k.log.Error("Failed to fetch order", zap.Error(err))
+		return models.Order{}, fmt.Errorf("failed to fetch order: %w", err)
+	}
+
+	result, err := convertKucoinOrder(&order)
 	if err != nil {
-		k.log.Error("Failed to fetch account balances", zap.Error(err))
-		return 0, fmt.Errorf("failed to fetch account balances: %w", err), 500
+		k.log.Error("Failed to parse order", zap.String("orderID", orderID), zap.Error(err))
+		return models.Order{}, fmt.Errorf("failed to fetch order: %w", err)
 	}
+	return result, nil
+}
+
+func (k *KucoinExchange) ListOpenOrders(ctx context.Context, symbol models.Symbol) ([]models.Order, error) {
+	k.log.Info("Listing Kucoin open orders", zap.String("symbol", symbol.String()))
+
+	params := map[string]string{"status": "active"}
+	if !symbol.IsZero() {
+		params["symbol"] = kucoinSymbol(symbol)
+	}
+
+	var result []models.Order
+	for page := int64(1); ; page++ {
+		orders, pagination, err := k.listOrders(ctx, params, &kucoin.PaginationParam{CurrentPage: page, PageSize: kucoinMaxPageSize})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, orders...)
+		if page >= pagination.TotalPage {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (k *KucoinExchange) ListOrderHistory(ctx context.Context, query models.OrderHistoryQuery) ([]models.Order, error) {
+	k.log.Info("Listing Kucoin order history", zap.String("symbol", query.Symbol.String()))
+
+	params := map[string]string{"status": "done"}
+	if !query.Symbol.IsZero() {
+		params["symbol"] = kucoinSymbol(query.Symbol)
+	}
+	if !query.StartTime.IsZero() {
+		params["startAt"] = strconv.FormatInt(query.StartTime.UnixMilli(), 10)
+	}
+	if !query.EndTime.IsZero() {
+		params["endAt"] = strconv.FormatInt(query.EndTime.UnixMilli(), 10)
+	}
+
+	orders, _, err := k.listOrders(ctx, params, &kucoin.PaginationParam{CurrentPage: int64(query.Page), PageSize: int64(query.Limit)})
+	return orders, err
+}
+
+func (k *KucoinExchange) listOrders(ctx context.Context, params map[string]string, pagination *kucoin.PaginationParam) ([]models.Order, *kucoin.PaginationModel, error) {
+	rsp, err := k.client.Orders(ctx, params, pagination)
+	if err != nil {
+		k.log.Error("Failed to list orders", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to list orders: %w", errs.Wrap(errs.ErrExchangeUnavailable, err))
+	}
+
+	var orders kucoin.OrdersModel
+	page, err := rsp.ReadPaginationData(&orders)
+	if err != nil {
+		k.log.Error("Failed to parse orders", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to list orders: %w", errs.Wrap(kucoinErrorKind(rsp), err))
+	}
+
+	result := make([]models.Order, 0, len(orders))
+	for _, o := range orders {
+		order, err := convertKucoinOrder(o)
+		if err != nil {
+			k.log.Error("Failed to parse order", zap.String("orderID", o.Id), zap.Error(err))
+			return nil, nil, fmt.Errorf("failed to list orders: %w", err)
+		}
+		result = append(result, order)
+	}
+	return result, page, nil
+}
+
+func (k *KucoinExchange) CancelAllOrders(ctx context.Context, symbol models.Symbol, side string) ([]models.CancelResult, error) {
+	k.log.Info("Cancelling all Kucoin orders",
+		zap.String("symbol", symbol.String()),
+		zap.String("side", side),
+	)
+
+	if side != "" {
+		return cancelAllOpenOrders(ctx, k, symbol, side)
+	}
+
+	params := map[string]string{}
+	if !symbol.IsZero() {
+		params["symbol"] = kucoinSymbol(symbol)
+	}
+
+	var canceled kucoin.CancelOrderResultModel
+	rsp, err := k.client.CancelOrders(ctx, params)
+	if err := readKucoinData(rsp, err, &canceled); err != nil {
+		k.log.Error("Failed to cancel orders", zap.Error(err))
+		return nil, fmt.Errorf("failed to cancel orders: %w", err)
+	}
+
+	results := make([]models.CancelResult, 0, len(canceled.CancelledOrderIds))
+	for _, id := range canceled.CancelledOrderIds {
+		results = append(results, cancelResult(id, symbol, nil))
+	}
+	k.log.Info("Orders cancelled successfully", zap.Int("count", len(results)))
+	return results, nil
+}
+
+func (k *KucoinExchange) GetFills(ctx context.Context, symbol models.Symbol, since time.Time) ([]models.Fill, error) {
+	k.log.Info("Fetching Kucoin fills", zap.String("symbol", symbol.String()), zap.Time("since", since))
+
+	params := map[string]string{
+		"startAt": strconv.FormatInt(since.UnixMilli(), 10),
+	}
+	if !symbol.IsZero() {
+		params["symbol"] = kucoinSymbol(symbol)
+	}
+
+	var fills []models.Fill
+	for page := int64(1); ; page++ {
+		rsp, err := k.client.Fills(ctx, params, &kucoin.PaginationParam{CurrentPage: page, PageSize: kucoinMaxPageSize})
+		if err != nil {
+			k.log.Error("Failed to fetch fills", zap.Error(err))
+			return nil, fmt.Errorf("failed to fetch fills: %w", errs.Wrap(errs.ErrExchangeUnavailable, err))
+		}
+
+		var items kucoin.FillsModel
+		pagination, err := rsp.ReadPaginationData(&items)
+		if err != nil {
+			k.log.Error("Failed to parse fills", zap.Error(err))
+			return nil, fmt.Errorf("failed to fetch fills: %w", errs.Wrap(kucoinErrorKind(rsp), err))
+		}
+		var p decimals
+		for _, f := range items {
+			fills = append(fills, models.Fill{
+				TradeID:   f.TradeId,
+				OrderID:   f.OrderId,
+				Symbol:    splitSymbol(f.Symbol, "-"),
+				Side:      strings.ToLower(f.Side),
+				Price:     p.parse(f.Price),
+				Quantity:  p.parse(f.Size),
+				Fee:       p.parse(f.Fee),
+				FeeAsset:  f.FeeCurrency,
+				Liquidity: liquidity(f.Liquidity == "maker"),
+				Timestamp: time.UnixMilli(f.CreatedAt),
+			})
+		}
+		if p.err != nil {
+			k.log.Error("Failed to parse fills", zap.Error(p.err))
+			return nil, fmt.Errorf("failed to fetch fills: %w", p.err)
+		}
+		if page >= pagination.TotalPage {
+			break
+		}
+	}
+	return fills, nil
+}
+
+func (k *KucoinExchange) GetBalance(ctx context.Context, asset string) (decimal.Decimal, error) {
+	k.log.Info("Fetching Kucoin balance", zap.String("asset", asset))
 
 	var accounts []kucoin.AccountModel
-	if err := rsp.ReadData(&accounts); err != nil {
-		k.log.Error("Failed to parse account data", zap.Error(err))
-		return 0, fmt.Errorf("failed to parse account data: %w", err), 500
+	rsp, err := k.client.Accounts(ctx, "", "")
+	if err := readKucoinData(rsp, err, &accounts); err != nil {
+		k.log.Error("Failed to fetch account balances", zap.Error(err))
+		return decimal.Zero, fmt.Errorf("failed to fetch account balances: %w", err)
 	}
 
 	for _, account := range accounts {
 		if account.Currency == strings.ToUpper(asset) {
-			balance, err := strconv.ParseFloat(account.Available, 64)
+			balance, err := decimal.NewFromString(account.Available)
 			if err != nil {
 				k.log.Error("Failed to parse balance", zap.String("asset", asset), zap.Error(err))
-				return 0, fmt.Errorf("failed to parse balance for %s: %w", asset, err), 500
+				return decimal.Zero, errs.New(errs.ErrInternal, "failed to parse balance for %s: %w", asset, err)
 			}
-			k.log.Info("Balance fetched", zap.String("asset", asset), zap.Float64("balance", balance))
-			return balance, nil, 200
+			k.log.Info("Balance fetched", zap.String("asset", asset), zap.Stringer("balance", balance))
+			return balance, nil
 		}
 	}
 
 	k.log.Warn("Asset not found", zap.String("asset", asset))
-	return 0, fmt.Errorf("asset %s not found", asset), 500
+	return decimal.Zero, errs.New(errs.ErrAssetNotFound, "asset %s not found", asset)
 }
 
-func (k *KucoinExchange) GetOrderBook(ctx context.Context, symbol string) (models.OrderBook, error, int) {
-	k.log.Info("Fetching Kucoin order book", zap.String("symbol", symbol))
+// GetBalances reports the trade account, the only one orders draw from.
+func (k *KucoinExchange) GetBalances(ctx context.Context) ([]models.Balance, error) {
+	var accounts []kucoin.AccountModel
+	rsp, err := k.client.Accounts(ctx, "", "trade")
+	if err := readKucoinData(rsp, err, &accounts); err != nil {
+		k.log.Error("Failed to fetch account balances", zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch account balances: %w", err)
+	}
 
-	rsp, err := k.client.AggregatedFullOrderBook(ctx, symbol)
-	if err != nil {
-		k.log.Error("Failed to fetch order book", zap.Error(err))
-		return models.OrderBook{}, fmt.Errorf("failed to fetch order book: %w", err), 500
+	var p decimals
+	var balances []models.Balance
+	for _, account := range accounts {
+		balances = appendBalance(balances, account.Currency, p.parse(account.Available), p.parse(account.Holds))
 	}
+	if p.err != nil {
+		k.log.Error("Failed to parse account balances", zap.Error(p.err))
+		return nil, fmt.Errorf("failed to fetch account balances: %w", p.err)
+	}
+	return balances, nil
+}
+
+// GetOrderBook uses KuCoin's 20 or 100 level snapshots when they cover
+// limit and the full book otherwise.
+func (k *KucoinExchange) GetOrderBook(ctx context.Context, symbol models.Symbol, limit int) (models.OrderBook, error) {
+	k.log.Info("Fetching Kucoin order book", zap.String("symbol", symbol.String()))
 
 	var kucoinOB kucoin.FullOrderBookModel
-	if err := rsp.ReadData(&kucoinOB); err != nil {
-		k.log.Error("Failed to parse order book", zap.Error(err))
-		return models.OrderBook{}, fmt.Errorf("failed to parse order book: %w", err), 500
+	var rsp *kucoin.ApiResponse
+	var err error
+	if limit > 0 && limit <= 100 {
+		rsp, err = k.client.AggregatedPartOrderBook(ctx, kucoinSymbol(symbol), int64(nativeDepth(limit, 20, 100)))
+	} else {
+		rsp, err = k.client.AggregatedFullOrderBook(ctx, kucoinSymbol(symbol))
+	}
+	if err := readKucoinData(rsp, err, &kucoinOB); err != nil {
+		k.log.Error("Failed to fetch order book", zap.Error(err))
+		return models.OrderBook{}, fmt.Errorf("failed to fetch order book: %w", err)
 	}
 
 	k.log.Info("Order book fetched successfully", zap.Int("asksCount", len(kucoinOB.Asks)), zap.Int("bidsCount", len(kucoinOB.Bids)))
 
-	return models.OrderBook{
-		Asks: convertEntries(kucoinOB.Asks),
-		Bids: convertEntries(kucoinOB.Bids),
-	}, nil, 200
+	var p decimals
+	sequence, _ := strconv.ParseInt(kucoinOB.Sequence, 10, 64)
+	book := models.OrderBook{
+		Asks:      convertEntries(&p, kucoinOB.Asks),
+		Bids:      convertEntries(&p, kucoinOB.Bids),
+		Sequence:  sequence,
+		Timestamp: time.UnixMilli(kucoinOB.Time),
+	}
+	if p.err != nil {
+		k.log.Error("Failed to parse order book", zap.Error(p.err))
+		return models.OrderBook{}, fmt.Errorf("failed to fetch order book: %w", p.err)
+	}
+	return book, nil
+}
+
+func (k *KucoinExchange) GetTicker(ctx context.Context, symbol models.Symbol) (models.Ticker, error) {
+	var stats kucoin.Stats24hrModel
+	rsp, err := k.client.Stats24hr(ctx, kucoinSymbol(symbol))
+	if err := readKucoinData(rsp, err, &stats); err != nil {
+		k.log.Error("Failed to fetch ticker", zap.String("symbol", symbol.String()), zap.Error(err))
+		return models.Ticker{}, fmt.Errorf("failed to fetch ticker: %w", err)
+	}
+	// Unknown symbols come back as a successful response with empty fields.
+	if stats.Last == "" {
+		return models.Ticker{}, errs.New(errs.ErrSymbolNotFound, "no ticker for %s", symbol)
+	}
+
+	// Markets without trades in the last day leave the range and change
+	// empty, and an empty side leaves its best price empty.
+	var p decimals
+	ticker := models.Ticker{
+		Symbol:             symbol,
+		LastPrice:          p.parse(stats.Last),
+		BidPrice:           p.optional(stats.Buy),
+		AskPrice:           p.optional(stats.Sell),
+		HighPrice:          p.optional(stats.High),
+		LowPrice:           p.optional(stats.Low),
+		Volume:             p.parse(stats.Vol),
+		QuoteVolume:        p.parse(stats.VolValue),
+		PriceChange:        p.optional(stats.ChangePrice),
+		PriceChangePercent: p.optional(stats.ChangeRate).Mul(hundred),
+		Timestamp:          time.UnixMilli(stats.Time),
+	}
+	if p.err != nil {
+		k.log.Error("Failed to parse ticker", zap.String("symbol", symbol.String()), zap.Error(p.err))
+		return models.Ticker{}, fmt.Errorf("failed to fetch ticker: %w", p.err)
+	}
+	return ticker, nil
+}
+
+var kucoinIntervals = map[models.CandleInterval]string{
+	models.CandleInterval1m:  "1min",
+	models.CandleInterval5m:  "5min",
+	models.CandleInterval15m: "15min",
+	models.CandleInterval1h:  "1hour",
+	models.CandleInterval4h:  "4hour",
+	models.CandleInterval1d:  "1day",
 }
 
-func convertEntries(entries [][]string) []models.OrderBookEntry {
-	result := make([]models.OrderBookEntry, 0, len(entries))
-	for _, entry := range entries {
-		if len(entry) < 2 {
-			continue
+// GetCandles pages through KuCoin's klines, whose rows are time, open,
+// close, high, low, volume and turnover, newest first.
+func (k *KucoinExchange) GetCandles(ctx context.Context, symbol models.Symbol, interval models.CandleInterval, from, to time.Time, limit int) ([]models.Candle, error) {
+	return pageCandles(interval, from, to, limit, kucoinMaxCandles, func(start, end time.Time, size int) ([]models.Candle, error) {
+		var rows kucoin.KLinesModel
+		rsp, err := k.client.KLines(ctx, kucoinSymbol(symbol), kucoinIntervals[interval], start.Unix(), end.Unix())
+		if err := readKucoinData(rsp, err, &rows); err != nil {
+			k.log.Error("Failed to fetch candles", zap.String("symbol", symbol.String()), zap.Error(err))
+			return nil, fmt.Errorf("failed to fetch candles: %w", err)
+		}
+
+		var p decimals
+		candles := make([]models.Candle, 0, len(rows))
+		for _, row := range rows {
+			if row == nil || len(*row) < 6 {
+				continue
+			}
+			r := *row
+			ts, err := strconv.ParseInt(r[0], 10, 64)
+			if err != nil {
+				continue
+			}
+			candles = append(candles, models.Candle{
+				OpenTime: time.Unix(ts, 0),
+				Open:     p.parse(r[1]),
+				Close:    p.parse(r[2]),
+				High:     p.parse(r[3]),
+				Low:      p.parse(r[4]),
+				Volume:   p.parse(r[5]),
+			})
+		}
+		if p.err != nil {
+			k.log.Error("Failed to parse candles", zap.String("symbol", symbol.String()), zap.Error(p.err))
+			return nil, fmt.Errorf("failed to fetch candles: %w", p.err)
 		}
-		price, _ := strconv.ParseFloat(entry[0], 64)
-		quantity, _ := strconv.ParseFloat(entry[1], 64)
-		result = append(result, models.OrderBookEntry{
-			Price:    price,
-			Quantity: quantity,
+		return candles, nil
+	})
+}
+
+// GetRecentTrades returns KuCoin's last 100 trades; the venue takes no limit.
+func (k *KucoinExchange) GetRecentTrades(ctx context.Context, symbol models.Symbol, limit int) ([]models.Trade, error) {
+	var res kucoin.TradeHistoriesModel
+	rsp, err := k.client.TradeHistories(ctx, kucoinSymbol(symbol))
+	if err := readKucoinData(rsp, err, &res); err != nil {
+		k.log.Error("Failed to fetch recent trades", zap.String("symbol", symbol.String()), zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch recent trades: %w", err)
+	}
+
+	var p decimals
+	trades := make([]models.Trade, 0, len(res))
+	for _, t := range res {
+		trades = append(trades, models.Trade{
+			TradeID:   t.Sequence,
+			Price:     p.parse(t.Price),
+			Quantity:  p.parse(t.Size),
+			Side:      strings.ToLower(t.Side),
+			Timestamp: time.Unix(0, t.Time),
+		})
+	}
+	if p.err != nil {
+		k.log.Error("Failed to parse recent trades", zap.String("symbol", symbol.String()), zap.Error(p.err))
+		return nil, fmt.Errorf("failed to fetch recent trades: %w", p.err)
+	}
+	return trades, nil
+}
+
+func (k *KucoinExchange) GetMarkets(ctx context.Context) ([]models.Market, error) {
+	k.log.Info("Fetching Kucoin markets")
+
+	var symbols kucoin.SymbolsModelV2
+	rsp, err := k.client.SymbolsV2(ctx, "")
+	if err := readKucoinData(rsp, err, &symbols); err != nil {
+		k.log.Error("Failed to fetch markets", zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch markets: %w", err)
+	}
+
+	// minFunds is null for markets without a notional minimum.
+	var p decimals
+	markets := make([]models.Market, 0, len(symbols))
+	for _, s := range symbols {
+		markets = append(markets, models.Market{
+			Symbol:      models.Symbol{Base: s.BaseCurrency, Quote: s.QuoteCurrency},
+			Tradable:    s.EnableTrading,
+			TickSize:    p.parse(s.PriceIncrement),
+			StepSize:    p.parse(s.BaseIncrement),
+			MinQuantity: p.parse(s.BaseMinSize),
+			MaxQuantity: p.parse(s.BaseMaxSize),
+			MinNotional: p.optional(s.MinFunds),
 		})
 	}
-	return result
+	if p.err != nil {
+		k.log.Error("Failed to parse markets", zap.Error(p.err))
+		return nil, fmt.Errorf("failed to fetch markets: %w", p.err)
+	}
+	return markets, nil
+}
+
+func convertKucoinOrder(o *kucoin.OrderModel) (models.Order, error) {
+	var p decimals
+	filled := p.parse(o.DealSize)
+	createdAt := time.UnixMilli(o.CreatedAt)
+	return models.Order{
+		OrderID:        o.Id,
+		Symbol:         splitSymbol(o.Symbol, "-"),
+		Side:           strings.ToLower(o.Side),
+		Type:           strings.ToLower(o.Type),
+		Status:         kucoinOrderStatus(o.IsActive, o.CancelExist, filled),
+		Price:          p.parse(o.Price),
+		Quantity:       p.parse(o.Size),
+		FilledQuantity: filled,
+		AvgFillPrice:   avgPrice(p.parse(o.DealFunds), filled),
+		CreatedAt:      createdAt,
+		UpdatedAt:      createdAt,
+	}, p.err
+}
+
+func kucoinSymbol(symbol models.Symbol) string {
+	return symbol.Join("-")
+}
+
+func kucoinOrderStatus(isActive, cancelExist bool, filled decimal.Decimal) models.OrderStatus {
+	switch {
+	case isActive && filled.IsPositive():
+		return models.OrderStatusPartiallyFilled
+	case isActive:
+		return models.OrderStatusNew
+	case cancelExist:
+		return models.OrderStatusCanceled
+	default:
+		return models.OrderStatusFilled
+	}
+}
+
+func readKucoinData(rsp *kucoin.ApiResponse, err error, v any) error {
+	if err != nil {
+		return errs.Wrap(errs.ErrExchangeUnavailable, err)
+	}
+	if err := rsp.ReadData(v); err != nil {
+		return errs.Wrap(kucoinErrorKind(rsp), err)
+	}
+	return nil
+}
+
+func kucoinErrorKind(rsp *kucoin.ApiResponse) *errs.Kind {
+	switch rsp.Code {
+	case "200004":
+		return errs.ErrInsufficientFunds
+	case "900001":
+		return errs.ErrSymbolNotFound
+	case "400001", "400002", "400003", "400004", "400005", "400006", "400007", "411100":
+		return errs.ErrAuthFailed
+	case "429000":
+		return errs.ErrRateLimited
+	case "400100":
+		if strings.Contains(strings.ToLower(rsp.Message), "not exist") {
+			return errs.ErrOrderNotFound
+		}
+		return errs.ErrInvalidRequest
+	}
+	if strings.HasPrefix(rsp.Code, "4") {
+		return errs.ErrInvalidRequest
+	}
+	return errs.ErrExchangeUnavailable
 }
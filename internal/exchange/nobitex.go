@@ -0,0 +1,574 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"eyeOne/config"
+	"eyeOne/internal/errs"
+	"eyeOne/internal/httpclient"
+	"eyeOne/models"
+)
+
+// Nobitex names its IRT markets BTCIRT but calls the currency rls in the
+// order and wallet APIs. Amounts on those markets are in rials.
+const (
+	nobitexRial       = "rls"
+	nobitexMaxCandles = 500
+)
+
+type NobitexExchange struct {
+	baseURL string
+	token   string
+	client  *httpclient.Client
+	logger  *zap.Logger
+}
+
+func NewNobitexExchange(client *httpclient.Client, logger *zap.Logger, cfg *config.Config) (*NobitexExchange, error) {
+	baseURL := "https://api.nobitex.ir"
+	if !cfg.NobitexEnv.IsProduction() {
+		baseURL = "https://testnetapi.nobitex.ir"
+	}
+	if cfg.NobitexBaseURL != "" {
+		baseURL = strings.TrimSuffix(cfg.NobitexBaseURL, "/")
+	}
+	logger.Info("Initialized Nobitex client", zap.String("environment", string(cfg.NobitexEnv)), zap.String("baseUrl", baseURL))
+	return &NobitexExchange{
+		baseURL: baseURL,
+		token:   cfg.NobitexToken,
+		client:  client,
+		logger:  logger,
+	}, nil
+}
+
+// GetOrderBook returns Nobitex's fixed-depth book; the venue takes no limit.
+func (n *NobitexExchange) GetOrderBook(ctx context.Context, symbol models.Symbol, limit int) (models.OrderBook, error) {
+	endpoint := fmt.Sprintf("%s/v3/orderbook/%s", n.baseURL, nobitexSymbol(symbol))
+	body, status, err := n.client.Get(ctx, endpoint, n.headers())
+	if err != nil || status < 200 || status >= 300 {
+		n.logger.Error("get order book failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return models.OrderBook{}, httpError("order book", status, body, err, errs.ErrSymbolNotFound)
+	}
+
+	var res models.NobitexOrderBookResponse
+	if err := nobitexResult("order book", body, &res, errs.ErrSymbolNotFound); err != nil {
+		n.logger.Error("failed to parse order book response", zap.Error(err))
+		return models.OrderBook{}, err
+	}
+
+	var p decimals
+	book := models.OrderBook{
+		Bids: convertEntries(&p, res.Bids),
+		Asks: convertEntries(&p, res.Asks),
+	}
+	if p.err != nil {
+		n.logger.Error("parse order book failed", zap.Error(p.err))
+		return models.OrderBook{}, p.err
+	}
+	if res.LastUpdate > 0 {
+		book.Timestamp = time.UnixMilli(res.LastUpdate)
+	}
+	return book, nil
+}
+
+func (n *NobitexExchange) GetTicker(ctx context.Context, symbol models.Symbol) (models.Ticker, error) {
+	src, dst := nobitexCurrency(symbol.Base), nobitexCurrency(symbol.Quote)
+	endpoint := fmt.Sprintf("%s/market/stats?srcCurrency=%s&dstCurrency=%s", n.baseURL, url.QueryEscape(src), url.QueryEscape(dst))
+	body, status, err := n.client.Get(ctx, endpoint, n.headers())
+	if err != nil || status < 200 || status >= 300 {
+		n.logger.Error("get stats failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return models.Ticker{}, httpError("stats", status, body, err, errs.ErrSymbolNotFound)
+	}
+
+	var res struct {
+		Stats map[string]models.NobitexStatsResponse `json:"stats"`
+	}
+	if err := nobitexResult("stats", body, &res, errs.ErrSymbolNotFound); err != nil {
+		n.logger.Error("failed to parse stats response", zap.Error(err))
+		return models.Ticker{}, err
+	}
+
+	stats, ok := res.Stats[src+"-"+dst]
+	if !ok || stats.Latest == "" {
+		return models.Ticker{}, errs.New(errs.ErrSymbolNotFound, "no ticker for %s", symbol)
+	}
+	var p decimals
+	ticker := models.Ticker{
+		Symbol:      symbol,
+		LastPrice:   p.parse(stats.Latest),
+		BidPrice:    p.parse(stats.BestBuy),
+		AskPrice:    p.parse(stats.BestSell),
+		HighPrice:   p.parse(stats.DayHigh),
+		LowPrice:    p.parse(stats.DayLow),
+		Volume:      p.parse(stats.VolumeSrc),
+		QuoteVolume: p.parse(stats.VolumeDst),
+		Timestamp:   time.Now(),
+	}
+	changeFromOpen(&ticker, p.parse(stats.DayOpen))
+	if p.err != nil {
+		n.logger.Error("parse stats failed", zap.Error(p.err))
+		return models.Ticker{}, p.err
+	}
+	return ticker, nil
+}
+
+func (n *NobitexExchange) GetCandles(ctx context.Context, symbol models.Symbol, interval models.CandleInterval, from, to time.Time, limit int) ([]models.Candle, error) {
+	return pageCandles(interval, from, to, limit, nobitexMaxCandles, func(start, end time.Time, size int) ([]models.Candle, error) {
+		params := url.Values{
+			"symbol":     {nobitexSymbol(symbol)},
+			"resolution": {udfResolution(interval)},
+			"from":       {strconv.FormatInt(start.Unix(), 10)},
+			"to":         {strconv.FormatInt(end.Unix(), 10)},
+		}
+		endpoint := fmt.Sprintf("%s/market/udf/history?%s", n.baseURL, params.Encode())
+		candles, err := getUDFHistory(ctx, n.client, endpoint, n.headers())
+		if err != nil {
+			n.logger.Error("get candles failed", zap.String("symbol", symbol.String()), zap.Error(err))
+		}
+		return candles, err
+	})
+}
+
+func (n *NobitexExchange) GetRecentTrades(ctx context.Context, symbol models.Symbol, limit int) ([]models.Trade, error) {
+	endpoint := fmt.Sprintf("%s/v2/trades/%s", n.baseURL, url.PathEscape(nobitexSymbol(symbol)))
+	body, status, err := n.client.Get(ctx, endpoint, n.headers())
+	if err != nil || status < 200 || status >= 300 {
+		n.logger.Error("get trades failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return nil, httpError("trades", status, body, err, errs.ErrSymbolNotFound)
+	}
+
+	var res struct {
+		Trades []models.NobitexPublicTradeResponse `json:"trades"`
+	}
+	if err := nobitexResult("trades", body, &res, errs.ErrSymbolNotFound); err != nil {
+		n.logger.Error("failed to parse trades response", zap.Error(err))
+		return nil, err
+	}
+
+	var p decimals
+	trades := make([]models.Trade, 0, len(res.Trades))
+	for _, t := range res.Trades {
+		trades = append(trades, models.Trade{
+			Price:     p.parse(t.Price),
+			Quantity:  p.parse(t.Volume),
+			Side:      strings.ToLower(t.Type),
+			Timestamp: time.UnixMilli(t.Time),
+		})
+	}
+	if p.err != nil {
+		n.logger.Error("parse trades failed", zap.Error(p.err))
+		return nil, p.err
+	}
+	return trades, nil
+}
+
+func (n *NobitexExchange) CreateOrder(ctx context.Context, symbol models.Symbol, side, orderType string, quantity, price decimal.Decimal) (string, error) {
+	endpoint := fmt.Sprintf("%s/market/orders/add", n.baseURL)
+	payload := map[string]interface{}{
+		"type":        strings.ToLower(side),
+		"execution":   strings.ToLower(orderType),
+		"srcCurrency": nobitexCurrency(symbol.Base),
+		"dstCurrency": nobitexCurrency(symbol.Quote),
+		"amount":      quantity.String(),
+	}
+	if !strings.EqualFold(orderType, "market") {
+		payload["price"] = price.String()
+	}
+
+	body, status, err := n.client.PostJSON(ctx, endpoint, payload, n.headers())
+	if err != nil || status < 200 || status >= 300 {
+		n.logger.Error("order creation failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return "", httpError("order creation", status, body, err, errs.ErrSymbolNotFound)
+	}
+
+	var res struct {
+		Order models.NobitexOrderResponse `json:"order"`
+	}
+	if err := nobitexResult("order creation", body, &res, errs.ErrSymbolNotFound); err != nil {
+		n.logger.Error("order creation rejected", zap.Error(err))
+		return "", err
+	}
+	return strconv.FormatInt(res.Order.ID, 10), nil
+}
+
+func (n *NobitexExchange) CancelOrder(ctx context.Context, symbol models.Symbol, orderID string) error {
+	id, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return errs.New(errs.ErrInvalidRequest, "invalid order ID %q", orderID)
+	}
+	endpoint := fmt.Sprintf("%s/market/orders/update-status", n.baseURL)
+	payload := map[string]interface{}{
+		"order":  id,
+		"status": "canceled",
+	}
+
+	body, status, err := n.client.PostJSON(ctx, endpoint, payload, n.headers())
+	if err != nil || status < 200 || status >= 300 {
+		n.logger.Error("cancel order failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return httpError("cancel order", status, body, err, errs.ErrOrderNotFound)
+	}
+	var res struct{}
+	if err := nobitexResult("cancel order", body, &res, errs.ErrOrderNotFound); err != nil {
+		n.logger.Error("cancel order rejected", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (n *NobitexExchange) GetOrder(ctx context.Context, symbol models.Symbol, orderID string) (models.Order, error) {
+	id, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return models.Order{}, errs.New(errs.ErrInvalidRequest, "invalid order ID %q", orderID)
+	}
+	endpoint := fmt.Sprintf("%s/market/orders/status", n.baseURL)
+	payload := map[string]interface{}{
+		"id": id,
+	}
+
+	body, status, err := n.client.PostJSON(ctx, endpoint, payload, n.headers())
+	if err != nil || status < 200 || status >= 300 {
+		n.logger.Error("get order failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return models.Order{}, httpError("get order", status, body, err, errs.ErrOrderNotFound)
+	}
+
+	var res struct {
+		Order models.NobitexOrderResponse `json:"order"`
+	}
+	if err := nobitexResult("get order", body, &res, errs.ErrOrderNotFound); err != nil {
+		n.logger.Error("unmarshal order failed", zap.Error(err))
+		return models.Order{}, err
+	}
+	order, err := convertNobitexOrder(res.Order)
+	if err != nil {
+		n.logger.Error("parse order failed", zap.Error(err))
+		return models.Order{}, err
+	}
+	return order, nil
+}
+
+func (n *NobitexExchange) ListOpenOrders(ctx context.Context, symbol models.Symbol) ([]models.Order, error) {
+	return n.listOrders(ctx, "open", symbol)
+}
+
+func (n *NobitexExchange) ListOrderHistory(ctx context.Context, query models.OrderHistoryQuery) ([]models.Order, error) {
+	orders, err := n.listOrders(ctx, "all", query.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.Order, 0, len(orders))
+	for _, o := range closedOrders(orders) {
+		if !query.StartTime.IsZero() && o.CreatedAt.Before(query.StartTime) {
+			continue
+		}
+		if !query.EndTime.IsZero() && o.CreatedAt.After(query.EndTime) {
+			continue
+		}
+		result = append(result, o)
+	}
+	return pageOrders(result, query.Page, query.Limit), nil
+}
+
+func (n *NobitexExchange) listOrders(ctx context.Context, state string, symbol models.Symbol) ([]models.Order, error) {
+	params := url.Values{
+		"status":  {state},
+		"details": {"2"},
+	}
+	if !symbol.IsZero() {
+		params.Set("srcCurrency", nobitexCurrency(symbol.Base))
+		params.Set("dstCurrency", nobitexCurrency(symbol.Quote))
+	}
+	endpoint := fmt.Sprintf("%s/market/orders/list?%s", n.baseURL, params.Encode())
+	body, status, err := n.client.Get(ctx, endpoint, n.headers())
+	if err != nil || status < 200 || status >= 300 {
+		n.logger.Error("list orders failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return nil, httpError("list orders", status, body, err, errs.ErrSymbolNotFound)
+	}
+
+	var res struct {
+		Orders []models.NobitexOrderResponse `json:"orders"`
+	}
+	if err := nobitexResult("list orders", body, &res, errs.ErrSymbolNotFound); err != nil {
+		n.logger.Error("unmarshal orders failed", zap.Error(err))
+		return nil, err
+	}
+
+	orders := make([]models.Order, 0, len(res.Orders))
+	for _, o := range res.Orders {
+		order, err := convertNobitexOrder(o)
+		if err != nil {
+			n.logger.Error("parse order failed", zap.Error(err))
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+func (n *NobitexExchange) CancelAllOrders(ctx context.Context, symbol models.Symbol, side string) ([]models.CancelResult, error) {
+	return cancelAllOpenOrders(ctx, n, symbol, side)
+}
+
+func (n *NobitexExchange) GetFills(ctx context.Context, symbol models.Symbol, since time.Time) ([]models.Fill, error) {
+	params := url.Values{}
+	if !symbol.IsZero() {
+		params.Set("srcCurrency", nobitexCurrency(symbol.Base))
+		params.Set("dstCurrency", nobitexCurrency(symbol.Quote))
+	}
+	endpoint := fmt.Sprintf("%s/market/trades/list?%s", n.baseURL, params.Encode())
+	body, status, err := n.client.Get(ctx, endpoint, n.headers())
+	if err != nil || status < 200 || status >= 300 {
+		n.logger.Error("list fills failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return nil, httpError("list fills", status, body, err, errs.ErrSymbolNotFound)
+	}
+
+	var res struct {
+		Trades []models.NobitexTradeResponse `json:"trades"`
+	}
+	if err := nobitexResult("list fills", body, &res, errs.ErrSymbolNotFound); err != nil {
+		n.logger.Error("unmarshal fills failed", zap.Error(err))
+		return nil, err
+	}
+
+	var p decimals
+	fills := make([]models.Fill, 0, len(res.Trades))
+	for _, t := range res.Trades {
+		timestamp := parseNobitexTime(t.Timestamp)
+		if timestamp.Before(since) {
+			continue
+		}
+		sym := models.Symbol{Base: canonicalNobitexAsset(t.SrcCurrency), Quote: canonicalNobitexAsset(t.DstCurrency)}
+		// Nobitex charges the fee in the currency received.
+		feeAsset := sym.Quote
+		if strings.EqualFold(t.Type, "buy") {
+			feeAsset = sym.Base
+		}
+		fills = append(fills, models.Fill{
+			TradeID:   strconv.FormatInt(t.ID, 10),
+			OrderID:   strconv.FormatInt(t.OrderID, 10),
+			Symbol:    sym,
+			Side:      strings.ToLower(t.Type),
+			Price:     p.parse(t.Price),
+			Quantity:  p.parse(t.Amount),
+			Fee:       p.parse(t.Fee),
+			FeeAsset:  feeAsset,
+			Liquidity: models.LiquidityTaker,
+			Timestamp: timestamp,
+		})
+	}
+	if p.err != nil {
+		n.logger.Error("parse fills failed", zap.Error(p.err))
+		return nil, p.err
+	}
+	return fills, nil
+}
+
+func (n *NobitexExchange) GetBalance(ctx context.Context, asset string) (decimal.Decimal, error) {
+	wallets, err := n.wallets(ctx)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	currency := nobitexCurrency(asset)
+	for _, w := range wallets {
+		if strings.EqualFold(w.Currency, currency) {
+			balance, err := decimal.NewFromString(w.ActiveBalance)
+			if err != nil {
+				n.logger.Error("parse balance failed", zap.String("balance", w.ActiveBalance), zap.Error(err))
+				return decimal.Zero, errs.Wrap(errs.ErrInternal, err)
+			}
+			return balance, nil
+		}
+	}
+	return decimal.Zero, errs.New(errs.ErrAssetNotFound, "asset %s not found", asset)
+}
+
+func (n *NobitexExchange) GetBalances(ctx context.Context) ([]models.Balance, error) {
+	wallets, err := n.wallets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var p decimals
+	var balances []models.Balance
+	for _, w := range wallets {
+		balances = appendBalance(balances, canonicalNobitexAsset(w.Currency), p.parse(w.ActiveBalance), p.parse(w.BlockedBalance))
+	}
+	if p.err != nil {
+		n.logger.Error("parse wallets failed", zap.Error(p.err))
+		return nil, p.err
+	}
+	return balances, nil
+}
+
+func (n *NobitexExchange) wallets(ctx context.Context) ([]models.NobitexWalletResponse, error) {
+	endpoint := fmt.Sprintf("%s/users/wallets/list", n.baseURL)
+	body, status, err := n.client.Get(ctx, endpoint, n.headers())
+	if err != nil || status < 200 || status >= 300 {
+		n.logger.Error("get wallets failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return nil, httpError("wallets", status, body, err, errs.ErrAssetNotFound)
+	}
+
+	var res struct {
+		Wallets []models.NobitexWalletResponse `json:"wallets"`
+	}
+	if err := nobitexResult("wallets", body, &res, errs.ErrAssetNotFound); err != nil {
+		n.logger.Error("unmarshal wallets failed", zap.Error(err))
+		return nil, err
+	}
+	return res.Wallets, nil
+}
+
+func (n *NobitexExchange) GetMarkets(ctx context.Context) ([]models.Market, error) {
+	endpoint := fmt.Sprintf("%s/v2/options", n.baseURL)
+	body, status, err := n.client.Get(ctx, endpoint, n.headers())
+	if err != nil || status < 200 || status >= 300 {
+		n.logger.Error("get markets failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return nil, httpError("markets", status, body, err, errs.ErrExchangeUnavailable)
+	}
+
+	var res struct {
+		Nobitex struct {
+			AmountPrecisions map[string]string `json:"amountPrecisions"`
+			PricePrecisions  map[string]string `json:"pricePrecisions"`
+		} `json:"nobitex"`
+	}
+	if err := nobitexResult("markets", body, &res, errs.ErrExchangeUnavailable); err != nil {
+		n.logger.Error("unmarshal markets failed", zap.Error(err))
+		return nil, err
+	}
+
+	// A market missing from amountPrecisions has no step.
+	var p decimals
+	markets := make([]models.Market, 0, len(res.Nobitex.PricePrecisions))
+	for native, tick := range res.Nobitex.PricePrecisions {
+		markets = append(markets, models.Market{
+			Symbol:   splitByQuote(native),
+			Tradable: true,
+			TickSize: p.parse(tick),
+			StepSize: p.optional(res.Nobitex.AmountPrecisions[native]),
+		})
+	}
+	if p.err != nil {
+		n.logger.Error("parse markets failed", zap.Error(p.err))
+		return nil, p.err
+	}
+	return markets, nil
+}
+
+func (n *NobitexExchange) headers() map[string]string {
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+	if n.token != "" {
+		headers["Authorization"] = "Token " + n.token
+	}
+	return headers
+}
+
+// convertNobitexOrder maps an order. Market orders report their price as
+// "market".
+func convertNobitexOrder(o models.NobitexOrderResponse) (models.Order, error) {
+	var p decimals
+	filled := p.parse(o.MatchedAmount)
+	createdAt := parseNobitexTime(o.CreatedAt)
+	price := decimal.Zero
+	if o.Price != "market" {
+		price = p.parse(o.Price)
+	}
+	return models.Order{
+		OrderID:        strconv.FormatInt(o.ID, 10),
+		Symbol:         models.Symbol{Base: canonicalNobitexAsset(o.SrcCurrency), Quote: canonicalNobitexAsset(o.DstCurrency)},
+		Side:           strings.ToLower(o.Type),
+		Type:           strings.ToLower(o.Execution),
+		Status:         nobitexOrderStatus(o.Status, filled),
+		Price:          price,
+		Quantity:       p.parse(o.Amount),
+		FilledQuantity: filled,
+		AvgFillPrice:   avgPrice(p.parse(o.TotalPrice), filled),
+		CreatedAt:      createdAt,
+		UpdatedAt:      createdAt,
+	}, p.err
+}
+
+// nobitexSymbol builds market names such as BTCIRT and BTCUSDT.
+func nobitexSymbol(symbol models.Symbol) string {
+	return symbol.Join("")
+}
+
+func nobitexCurrency(asset string) string {
+	if strings.EqualFold(asset, "IRT") {
+		return nobitexRial
+	}
+	return strings.ToLower(asset)
+}
+
+func canonicalNobitexAsset(currency string) string {
+	if strings.EqualFold(currency, nobitexRial) {
+		return "IRT"
+	}
+	return strings.ToUpper(currency)
+}
+
+func nobitexOrderStatus(status string, filled decimal.Decimal) models.OrderStatus {
+	switch strings.ToLower(status) {
+	case "done":
+		return models.OrderStatusFilled
+	case "canceled", "cancelled", "inactive":
+		return models.OrderStatusCanceled
+	case "rejected":
+		return models.OrderStatusRejected
+	}
+	if filled.IsPositive() {
+		return models.OrderStatusPartiallyFilled
+	}
+	return models.OrderStatusNew
+}
+
+func parseNobitexTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// nobitexResult decodes a response body. Nobitex reports most failures with
+// HTTP 200 and {"status": "failed", "code": ...}, so the status is checked first.
+func nobitexResult(op string, body []byte, result any, notFound *errs.Kind) error {
+	var envelope struct {
+		Status  string `json:"status"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return errs.Wrap(errs.ErrInternal, err)
+	}
+	if envelope.Status != "ok" {
+		return errs.New(nobitexErrorKind(envelope.Code, notFound), "%s: %s %s", op, envelope.Code, envelope.Message)
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return errs.Wrap(errs.ErrInternal, err)
+	}
+	return nil
+}
+
+func nobitexErrorKind(code string, notFound *errs.Kind) *errs.Kind {
+	switch {
+	case strings.Contains(code, "Balance"):
+		return errs.ErrInsufficientFunds
+	case strings.Contains(code, "NotFound"), strings.Contains(code, "Invalid") && strings.Contains(code, "Market"):
+		return notFound
+	case strings.Contains(code, "TooMany"):
+		return errs.ErrRateLimited
+	}
+	return errs.ErrInvalidRequest
+}
@@ -0,0 +1,321 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"eyeOne/config"
+	"eyeOne/internal/errs"
+	"eyeOne/models"
+)
+
+var btcIRT = models.Symbol{Base: "BTC", Quote: "IRT"}
+
+func newNobitexTestExchange(t *testing.T, handler http.HandlerFunc) *NobitexExchange {
+	return newTestAdapter(t, handler, NewNobitexExchange, func(baseURL string) *config.Config {
+		return &config.Config{NobitexEnv: models.EnvironmentTestnet, NobitexBaseURL: baseURL, NobitexToken: "token"}
+	})
+}
+
+// replay serves a recorded response from testdata/nobitex.
+func replay(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", "nobitex", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return body
+}
+
+func TestNobitexSymbolTranslation(t *testing.T) {
+	if got := nobitexSymbol(btcIRT); got != "BTCIRT" {
+		t.Errorf("nobitexSymbol(BTC/IRT) = %q, want BTCIRT", got)
+	}
+	if got := nobitexSymbol(btcUSDT); got != "BTCUSDT" {
+		t.Errorf("nobitexSymbol(BTC/USDT) = %q, want BTCUSDT", got)
+	}
+	if got := splitByQuote("BTCIRT"); got != btcIRT {
+		t.Errorf("splitByQuote(BTCIRT) = %v, want BTC/IRT", got)
+	}
+
+	currencies := []struct{ asset, currency string }{
+		{"IRT", "rls"},
+		{"irt", "rls"},
+		{"BTC", "btc"},
+		{"USDT", "usdt"},
+	}
+	for _, c := range currencies {
+		if got := nobitexCurrency(c.asset); got != c.currency {
+			t.Errorf("nobitexCurrency(%s) = %q, want %q", c.asset, got, c.currency)
+		}
+	}
+
+	assets := []struct{ currency, asset string }{
+		{"rls", "IRT"},
+		{"RLS", "IRT"},
+		{"btc", "BTC"},
+		{"usdt", "USDT"},
+	}
+	for _, a := range assets {
+		if got := canonicalNobitexAsset(a.currency); got != a.asset {
+			t.Errorf("canonicalNobitexAsset(%s) = %q, want %q", a.currency, got, a.asset)
+		}
+	}
+}
+
+func TestNobitexGetOrderBook(t *testing.T) {
+	n := newNobitexTestExchange(t, func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3/orderbook/BTCIRT" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		rw.Write(replay(t, "orderbook.json"))
+	})
+
+	book, err := n.GetOrderBook(context.Background(), btcIRT, 0)
+	if err != nil {
+		t.Fatalf("GetOrderBook: %v", err)
+	}
+	if len(book.Asks) != 2 || len(book.Bids) != 2 {
+		t.Fatalf("got %d asks and %d bids, want 2 and 2", len(book.Asks), len(book.Bids))
+	}
+	assertDecimal(t, "best ask", book.Asks[0].Price, "6521000000")
+	assertDecimal(t, "best bid size", book.Bids[0].Quantity, "0.05")
+	if book.Timestamp.UnixMilli() != 1760680800123 {
+		t.Errorf("timestamp = %v, want the lastUpdate of the fixture", book.Timestamp)
+	}
+}
+
+func TestNobitexGetTicker(t *testing.T) {
+	n := newNobitexTestExchange(t, func(rw http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/market/stats" || q.Get("srcCurrency") != "btc" || q.Get("dstCurrency") != "rls" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		rw.Write(replay(t, "stats.json"))
+	})
+
+	ticker, err := n.GetTicker(context.Background(), btcIRT)
+	if err != nil {
+		t.Fatalf("GetTicker: %v", err)
+	}
+	if ticker.Symbol != btcIRT {
+		t.Errorf("symbol = %v, want BTC/IRT", ticker.Symbol)
+	}
+	assertDecimal(t, "last price", ticker.LastPrice, "6520000000")
+	assertDecimal(t, "bid", ticker.BidPrice, "6518000000")
+	assertDecimal(t, "quote volume", ticker.QuoteVolume, "81250000000")
+}
+
+func TestNobitexBalances(t *testing.T) {
+	n := newNobitexTestExchange(t, func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/wallets/list" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Token token" {
+			t.Errorf("missing token header")
+		}
+		rw.Write(replay(t, "wallets.json"))
+	})
+
+	free, err := n.GetBalance(context.Background(), "IRT")
+	if err != nil {
+		t.Fatalf("GetBalance: %v", err)
+	}
+	assertDecimal(t, "free IRT", free, "100000000")
+
+	if _, err := n.GetBalance(context.Background(), "ETH"); !errors.Is(err, errs.ErrAssetNotFound) {
+		t.Errorf("GetBalance(ETH) error = %v, want %v", err, errs.ErrAssetNotFound)
+	}
+
+	balances, err := n.GetBalances(context.Background())
+	if err != nil {
+		t.Fatalf("GetBalances: %v", err)
+	}
+	if len(balances) != 2 || balances[0].Asset != "IRT" || balances[1].Asset != "BTC" {
+		t.Fatalf("got %+v, want the non-empty IRT and BTC balances", balances)
+	}
+	assertDecimal(t, "locked IRT", balances[0].Locked, "50000000")
+}
+
+func TestNobitexCreateOrder(t *testing.T) {
+	tests := []struct {
+		name      string
+		orderType string
+		wantPrice bool
+	}{
+		{name: "limit", orderType: "limit", wantPrice: true},
+		{name: "market", orderType: "market", wantPrice: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newNobitexTestExchange(t, func(rw http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/market/orders/add" {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				var payload map[string]string
+				if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+					t.Fatalf("decode payload: %v", err)
+				}
+				if payload["srcCurrency"] != "btc" || payload["dstCurrency"] != "rls" || payload["type"] != "buy" || payload["amount"] != "0.01" {
+					t.Errorf("unexpected payload %v", payload)
+				}
+				if _, ok := payload["price"]; ok != tt.wantPrice {
+					t.Errorf("price sent = %v, want %v", ok, tt.wantPrice)
+				}
+				rw.Write(replay(t, "order_add.json"))
+			})
+
+			id, err := n.CreateOrder(context.Background(), btcIRT, "buy", tt.orderType, decimal.RequireFromString("0.01"), decimal.RequireFromString("6500000000"))
+			if err != nil {
+				t.Fatalf("CreateOrder: %v", err)
+			}
+			if id != "25" {
+				t.Errorf("order ID = %q, want 25", id)
+			}
+		})
+	}
+}
+
+func TestNobitexOrderStatus(t *testing.T) {
+	n := newNobitexTestExchange(t, func(rw http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/market/orders/list" || q.Get("status") != "all" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		rw.Write(replay(t, "orders_list.json"))
+	})
+
+	orders, err := n.listOrders(context.Background(), "all", models.Symbol{})
+	if err != nil {
+		t.Fatalf("listOrders: %v", err)
+	}
+
+	want := []struct {
+		id     string
+		symbol models.Symbol
+		status models.OrderStatus
+		price  string
+	}{
+		{"21", btcIRT, models.OrderStatusNew, "6500000000"},
+		{"22", btcIRT, models.OrderStatusPartiallyFilled, "6600000000"},
+		{"23", btcIRT, models.OrderStatusFilled, "0"},
+		{"24", models.Symbol{Base: "USDT", Quote: "IRT"}, models.OrderStatusCanceled, "1100000"},
+	}
+	if len(orders) != len(want) {
+		t.Fatalf("got %d orders, want %d", len(orders), len(want))
+	}
+	for i, w := range want {
+		o := orders[i]
+		if o.OrderID != w.id || o.Symbol != w.symbol || o.Status != w.status {
+			t.Errorf("order %d = %s %v %s, want %s %v %s", i, o.OrderID, o.Symbol, o.Status, w.id, w.symbol, w.status)
+		}
+		assertDecimal(t, "price of order "+w.id, o.Price, w.price)
+	}
+	assertDecimal(t, "average fill price of order 23", orders[2].AvgFillPrice, "6520000000")
+
+	statuses := []struct {
+		status string
+		filled string
+		want   models.OrderStatus
+	}{
+		{"Active", "0", models.OrderStatusNew},
+		{"Active", "0.5", models.OrderStatusPartiallyFilled},
+		{"Done", "1", models.OrderStatusFilled},
+		{"Canceled", "0.5", models.OrderStatusCanceled},
+		{"Inactive", "0", models.OrderStatusCanceled},
+		{"Rejected", "0", models.OrderStatusRejected},
+	}
+	for _, s := range statuses {
+		if got := nobitexOrderStatus(s.status, decimal.RequireFromString(s.filled)); got != s.want {
+			t.Errorf("nobitexOrderStatus(%s, %s) = %s, want %s", s.status, s.filled, got, s.want)
+		}
+	}
+}
+
+func TestNobitexFailedEnvelopes(t *testing.T) {
+	create := func(n *NobitexExchange) error {
+		_, err := n.CreateOrder(context.Background(), btcIRT, "buy", "limit", decimal.RequireFromString("1"), decimal.RequireFromString("6500000000"))
+		return err
+	}
+	cancel := func(n *NobitexExchange) error {
+		return n.CancelOrder(context.Background(), btcIRT, "25")
+	}
+	orderBook := func(n *NobitexExchange) error {
+		_, err := n.GetOrderBook(context.Background(), models.Symbol{Base: "FOO", Quote: "IRT"}, 0)
+		return err
+	}
+
+	tests := []struct {
+		name string
+		call func(*NobitexExchange) error
+		body string
+		want *errs.Kind
+	}{
+		{
+			name: "recorded rejection",
+			call: create,
+			body: string(replay(t, "order_failed.json")),
+			want: errs.ErrInvalidRequest,
+		},
+		{
+			name: "insufficient balance",
+			call: create,
+			body: `{"status":"failed","code":"InsufficientBalance","message":"Insufficient Balance"}`,
+			want: errs.ErrInsufficientFunds,
+		},
+		{
+			name: "unknown market",
+			call: create,
+			body: `{"status":"failed","code":"InvalidMarketPair","message":"Market Validation Failed"}`,
+			want: errs.ErrSymbolNotFound,
+		},
+		{
+			name: "unknown order",
+			call: cancel,
+			body: `{"status":"failed","code":"OrderNotFound","message":"Order not found"}`,
+			want: errs.ErrOrderNotFound,
+		},
+		{
+			name: "unknown order book",
+			call: orderBook,
+			body: `{"status":"failed","code":"NotFound","message":"Market not found"}`,
+			want: errs.ErrSymbolNotFound,
+		},
+		{
+			name: "rate limited",
+			call: cancel,
+			body: `{"status":"failed","code":"TooManyRequests","message":"Too many requests"}`,
+			want: errs.ErrRateLimited,
+		},
+		{
+			name: "malformed book level",
+			call: orderBook,
+			body: `{"status":"ok","asks":[["6521000000","0.012"],["6525000000,5","0.3"]],"bids":[]}`,
+			want: errs.ErrInternal,
+		},
+		{
+			name: "unreadable body",
+			call: create,
+			body: `<html>maintenance</html>`,
+			want: errs.ErrInternal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newNobitexTestExchange(t, func(rw http.ResponseWriter, r *http.Request) {
+				rw.Write([]byte(tt.body))
+			})
+
+			if err := tt.call(n); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v (%s), want %s", err, errs.Code(err), tt.want.Code())
+			}
+		})
+	}
+}
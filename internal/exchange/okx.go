@@ -0,0 +1,599 @@
+package exchange
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"eyeOne/config"
+	"eyeOne/internal/errs"
+	"eyeOne/internal/httpclient"
+	"eyeOne/models"
+)
+
+const (
+	okxBookDepth       = 400
+	okxHistoryLimit    = 100
+	okxMaxHistoryPages = 10
+	okxMaxCandles      = 100
+	okxMaxTrades       = 500
+)
+
+type OKXExchange struct {
+	baseURL string
+	client  *httpclient.Client
+	signer  httpclient.Signer
+	logger  *zap.Logger
+
+	// simulated routes requests to OKX demo trading, which shares the
+	// production host and is selected per request by header.
+	simulated bool
+}
+
+func NewOKXExchange(client *httpclient.Client, logger *zap.Logger, cfg *config.Config) (*OKXExchange, error) {
+	o := &OKXExchange{
+		baseURL:   "https://www.okx.com",
+		client:    client,
+		logger:    logger,
+		simulated: !cfg.OKXEnv.IsProduction(),
+	}
+	logger.Info("Initialized OKX client", zap.String("environment", string(cfg.OKXEnv)), zap.Bool("simulated", o.simulated))
+	if cfg.HasOKXCredentials() {
+		o.signer = newOKXSigner(cfg.OKXAPIKey, cfg.OKXSecretKey, cfg.OKXPassphrase)
+	}
+	return o, nil
+}
+
+// newOKXSigner signs timestamp + method + request path + body with
+// HMAC-SHA256 and sends it base64 encoded next to the key and passphrase.
+func newOKXSigner(apiKey, secretKey, passphrase string) httpclient.Signer {
+	return &httpclient.HMACSigner{
+		Secret: []byte(secretKey),
+		Hash:   sha256.New,
+		Encode: base64.StdEncoding.EncodeToString,
+		Timestamp: func(t time.Time) string {
+			return t.UTC().Format("2006-01-02T15:04:05.000Z")
+		},
+		Message: func(timestamp string, req *http.Request, body []byte) string {
+			return timestamp + req.Method + httpclient.RequestTarget(req) + string(body)
+		},
+		Apply: func(header http.Header, timestamp, signature string) {
+			header.Set("OK-ACCESS-KEY", apiKey)
+			header.Set("OK-ACCESS-SIGN", signature)
+			header.Set("OK-ACCESS-TIMESTAMP", timestamp)
+			header.Set("OK-ACCESS-PASSPHRASE", passphrase)
+		},
+	}
+}
+
+func (o *OKXExchange) GetOrderBook(ctx context.Context, symbol models.Symbol, limit int) (models.OrderBook, error) {
+	depth := okxBookDepth
+	if limit > 0 {
+		depth = min(limit, okxBookDepth)
+	}
+	params := url.Values{
+		"instId": {okxSymbol(symbol)},
+		"sz":     {strconv.Itoa(depth)},
+	}
+	var books []models.OKXOrderBookResponse
+	if err := o.call(ctx, http.MethodGet, "/api/v5/market/books", params, nil, &books, errs.ErrSymbolNotFound); err != nil {
+		o.logger.Error("get order book failed", zap.String("symbol", symbol.String()), zap.Error(err))
+		return models.OrderBook{}, err
+	}
+	if len(books) == 0 {
+		return models.OrderBook{}, errs.New(errs.ErrSymbolNotFound, "no order book for %s", symbol)
+	}
+
+	// Levels carry price, size, a deprecated field and the order count;
+	// only the first two are kept.
+	var p decimals
+	book := models.OrderBook{
+		Bids:      convertEntries(&p, books[0].Bids),
+		Asks:      convertEntries(&p, books[0].Asks),
+		Timestamp: parseMillis(books[0].Ts),
+	}
+	if p.err != nil {
+		o.logger.Error("parse order book failed", zap.String("symbol", symbol.String()), zap.Error(p.err))
+		return models.OrderBook{}, p.err
+	}
+	return book, nil
+}
+
+func (o *OKXExchange) GetTicker(ctx context.Context, symbol models.Symbol) (models.Ticker, error) {
+	params := url.Values{"instId": {okxSymbol(symbol)}}
+	var tickers []models.OKXTickerResponse
+	if err := o.call(ctx, http.MethodGet, "/api/v5/market/ticker", params, nil, &tickers, errs.ErrSymbolNotFound); err != nil {
+		o.logger.Error("get ticker failed", zap.String("symbol", symbol.String()), zap.Error(err))
+		return models.Ticker{}, err
+	}
+	if len(tickers) == 0 {
+		return models.Ticker{}, errs.New(errs.ErrSymbolNotFound, "no ticker for %s", symbol)
+	}
+
+	// An empty side of the book leaves its best price empty.
+	var p decimals
+	t := tickers[0]
+	ticker := models.Ticker{
+		Symbol:      symbol,
+		LastPrice:   p.parse(t.Last),
+		BidPrice:    p.optional(t.BidPx),
+		AskPrice:    p.optional(t.AskPx),
+		HighPrice:   p.parse(t.High24h),
+		LowPrice:    p.parse(t.Low24h),
+		Volume:      p.parse(t.Vol24h),
+		QuoteVolume: p.parse(t.VolCcy24h),
+		Timestamp:   parseMillis(t.Ts),
+	}
+	changeFromOpen(&ticker, p.parse(t.Open24h))
+	if p.err != nil {
+		o.logger.Error("parse ticker failed", zap.String("symbol", symbol.String()), zap.Error(p.err))
+		return models.Ticker{}, p.err
+	}
+	return ticker, nil
+}
+
+var okxBars = map[models.CandleInterval]string{
+	models.CandleInterval1m:  "1m",
+	models.CandleInterval5m:  "5m",
+	models.CandleInterval15m: "15m",
+	models.CandleInterval1h:  "1H",
+	models.CandleInterval4h:  "4H",
+	models.CandleInterval1d:  "1Dutc",
+}
+
+// GetCandles pages with OKX's exclusive after/before cursors, which are
+// open times in milliseconds.
+func (o *OKXExchange) GetCandles(ctx context.Context, symbol models.Symbol, interval models.CandleInterval, from, to time.Time, limit int) ([]models.Candle, error) {
+	return pageCandles(interval, from, to, limit, okxMaxCandles, func(start, end time.Time, size int) ([]models.Candle, error) {
+		params := url.Values{
+			"instId": {okxSymbol(symbol)},
+			"bar":    {okxBars[interval]},
+			"after":  {strconv.FormatInt(end.UnixMilli(), 10)},
+			"before": {strconv.FormatInt(start.UnixMilli()-1, 10)},
+			"limit":  {strconv.Itoa(size)},
+		}
+		var rows [][]string
+		if err := o.call(ctx, http.MethodGet, "/api/v5/market/history-candles", params, nil, &rows, errs.ErrSymbolNotFound); err != nil {
+			o.logger.Error("get candles failed", zap.String("symbol", symbol.String()), zap.Error(err))
+			return nil, err
+		}
+		return rowCandles(rows)
+	})
+}
+
+func (o *OKXExchange) GetRecentTrades(ctx context.Context, symbol models.Symbol, limit int) ([]models.Trade, error) {
+	params := url.Values{
+		"instId": {okxSymbol(symbol)},
+		"limit":  {strconv.Itoa(min(limit, okxMaxTrades))},
+	}
+	var res []models.OKXTradeResponse
+	if err := o.call(ctx, http.MethodGet, "/api/v5/market/trades", params, nil, &res, errs.ErrSymbolNotFound); err != nil {
+		o.logger.Error("get recent trades failed", zap.String("symbol", symbol.String()), zap.Error(err))
+		return nil, err
+	}
+
+	var p decimals
+	trades := make([]models.Trade, 0, len(res))
+	for _, t := range res {
+		trades = append(trades, models.Trade{
+			TradeID:   t.TradeID,
+			Price:     p.parse(t.Px),
+			Quantity:  p.parse(t.Sz),
+			Side:      strings.ToLower(t.Side),
+			Timestamp: parseMillis(t.Ts),
+		})
+	}
+	if p.err != nil {
+		o.logger.Error("parse recent trades failed", zap.String("symbol", symbol.String()), zap.Error(p.err))
+		return nil, p.err
+	}
+	return trades, nil
+}
+
+func (o *OKXExchange) CreateOrder(ctx context.Context, symbol models.Symbol, side, orderType string, quantity, price decimal.Decimal) (string, error) {
+	payload := map[string]string{
+		"instId":  okxSymbol(symbol),
+		"tdMode":  "cash",
+		"side":    strings.ToLower(side),
+		"ordType": strings.ToLower(orderType),
+		"sz":      quantity.String(),
+	}
+	if strings.EqualFold(orderType, "market") {
+		// Market buys are sized in the quote currency unless told otherwise.
+		payload["tgtCcy"] = "base_ccy"
+	} else {
+		payload["px"] = price.String()
+	}
+
+	var results []okxOrderAck
+	if err := o.call(ctx, http.MethodPost, "/api/v5/trade/order", nil, payload, &results, errs.ErrSymbolNotFound); err != nil {
+		o.logger.Error("order creation failed", zap.String("symbol", symbol.String()), zap.Error(err))
+		return "", err
+	}
+	if len(results) == 0 {
+		return "", errs.New(errs.ErrInternal, "okx returned no order")
+	}
+	if err := results[0].err("order creation", errs.ErrSymbolNotFound); err != nil {
+		o.logger.Error("order creation rejected", zap.String("symbol", symbol.String()), zap.Error(err))
+		return "", err
+	}
+	o.logger.Info("Order created", zap.String("symbol", symbol.String()), zap.String("orderId", results[0].OrdID))
+	return results[0].OrdID, nil
+}
+
+func (o *OKXExchange) CancelOrder(ctx context.Context, symbol models.Symbol, orderID string) error {
+	if symbol.IsZero() {
+		return errs.New(errs.ErrInvalidRequest, "symbol is required to cancel okx orders")
+	}
+	payload := map[string]string{
+		"instId": okxSymbol(symbol),
+		"ordId":  orderID,
+	}
+
+	var results []okxOrderAck
+	if err := o.call(ctx, http.MethodPost, "/api/v5/trade/cancel-order", nil, payload, &results, errs.ErrOrderNotFound); err != nil {
+		o.logger.Error("cancel order failed", zap.String("orderId", orderID), zap.Error(err))
+		return err
+	}
+	if len(results) > 0 {
+		if err := results[0].err("cancel order", errs.ErrOrderNotFound); err != nil {
+			o.logger.Error("cancel order rejected", zap.String("orderId", orderID), zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *OKXExchange) GetOrder(ctx context.Context, symbol models.Symbol, orderID string) (models.Order, error) {
+	if symbol.IsZero() {
+		return models.Order{}, errs.New(errs.ErrInvalidRequest, "symbol is required to look up okx orders")
+	}
+	params := url.Values{
+		"instId": {okxSymbol(symbol)},
+		"ordId":  {orderID},
+	}
+
+	var orders []models.OKXOrderResponse
+	if err := o.call(ctx, http.MethodGet, "/api/v5/trade/order", params, nil, &orders, errs.ErrOrderNotFound); err != nil {
+		o.logger.Error("get order failed", zap.String("orderId", orderID), zap.Error(err))
+		return models.Order{}, err
+	}
+	if len(orders) == 0 {
+		return models.Order{}, errs.New(errs.ErrOrderNotFound, "order %s not found", orderID)
+	}
+	order, err := convertOKXOrder(orders[0])
+	if err != nil {
+		o.logger.Error("parse order failed", zap.String("orderId", orderID), zap.Error(err))
+		return models.Order{}, err
+	}
+	return order, nil
+}
+
+func (o *OKXExchange) ListOpenOrders(ctx context.Context, symbol models.Symbol) ([]models.Order, error) {
+	params := url.Values{"instType": {"SPOT"}}
+	if !symbol.IsZero() {
+		params.Set("instId", okxSymbol(symbol))
+	}
+
+	var orders []models.OKXOrderResponse
+	if err := o.call(ctx, http.MethodGet, "/api/v5/trade/orders-pending", params, nil, &orders, errs.ErrSymbolNotFound); err != nil {
+		o.logger.Error("list open orders failed", zap.Error(err))
+		return nil, err
+	}
+
+	result := make([]models.Order, 0, len(orders))
+	for _, order := range orders {
+		converted, err := convertOKXOrder(order)
+		if err != nil {
+			o.logger.Error("parse open orders failed", zap.Error(err))
+			return nil, err
+		}
+		result = append(result, converted)
+	}
+	return result, nil
+}
+
+func (o *OKXExchange) ListOrderHistory(ctx context.Context, query models.OrderHistoryQuery) ([]models.Order, error) {
+	params := url.Values{
+		"instType": {"SPOT"},
+		"limit":    {strconv.Itoa(okxHistoryLimit)},
+	}
+	if !query.Symbol.IsZero() {
+		params.Set("instId", okxSymbol(query.Symbol))
+	}
+	if !query.StartTime.IsZero() {
+		params.Set("begin", strconv.FormatInt(query.StartTime.UnixMilli(), 10))
+	}
+	if !query.EndTime.IsZero() {
+		params.Set("end", strconv.FormatInt(query.EndTime.UnixMilli(), 10))
+	}
+
+	// OKX pages backwards from the last order ID seen.
+	want := query.Page * query.Limit
+	var result []models.Order
+	for page := 0; page < okxMaxHistoryPages && len(result) < want; page++ {
+		var orders []models.OKXOrderResponse
+		if err := o.call(ctx, http.MethodGet, "/api/v5/trade/orders-history", params, nil, &orders, errs.ErrSymbolNotFound); err != nil {
+			o.logger.Error("list order history failed", zap.Error(err))
+			return nil, err
+		}
+		for _, order := range orders {
+			converted, err := convertOKXOrder(order)
+			if err != nil {
+				o.logger.Error("parse order history failed", zap.Error(err))
+				return nil, err
+			}
+			result = append(result, converted)
+		}
+		if len(orders) < okxHistoryLimit {
+			break
+		}
+		params.Set("after", orders[len(orders)-1].OrdID)
+	}
+	return pageOrders(closedOrders(result), query.Page, query.Limit), nil
+}
+
+func (o *OKXExchange) CancelAllOrders(ctx context.Context, symbol models.Symbol, side string) ([]models.CancelResult, error) {
+	return cancelAllOpenOrders(ctx, o, symbol, side)
+}
+
+func (o *OKXExchange) GetFills(ctx context.Context, symbol models.Symbol, since time.Time) ([]models.Fill, error) {
+	params := url.Values{
+		"instType": {"SPOT"},
+		"begin":    {strconv.FormatInt(since.UnixMilli(), 10)},
+	}
+	if !symbol.IsZero() {
+		params.Set("instId", okxSymbol(symbol))
+	}
+
+	var items []models.OKXFillResponse
+	if err := o.call(ctx, http.MethodGet, "/api/v5/trade/fills", params, nil, &items, errs.ErrSymbolNotFound); err != nil {
+		o.logger.Error("list fills failed", zap.Error(err))
+		return nil, err
+	}
+
+	var p decimals
+	fills := make([]models.Fill, 0, len(items))
+	for _, f := range items {
+		fills = append(fills, models.Fill{
+			TradeID:   f.TradeID,
+			OrderID:   f.OrdID,
+			Symbol:    splitSymbol(f.InstID, "-"),
+			Side:      strings.ToLower(f.Side),
+			Price:     p.parse(f.FillPx),
+			Quantity:  p.parse(f.FillSz),
+			Fee:       p.parse(f.Fee).Abs(),
+			FeeAsset:  f.FeeCcy,
+			Liquidity: liquidity(f.ExecType == "M"),
+			Timestamp: parseMillis(f.Ts),
+		})
+	}
+	if p.err != nil {
+		o.logger.Error("parse fills failed", zap.Error(p.err))
+		return nil, p.err
+	}
+	return fills, nil
+}
+
+func (o *OKXExchange) GetBalance(ctx context.Context, asset string) (decimal.Decimal, error) {
+	params := url.Values{"ccy": {strings.ToUpper(asset)}}
+
+	var accounts []struct {
+		Details []struct {
+			Ccy      string `json:"ccy"`
+			AvailBal string `json:"availBal"`
+		} `json:"details"`
+	}
+	if err := o.call(ctx, http.MethodGet, "/api/v5/account/balance", params, nil, &accounts, errs.ErrAssetNotFound); err != nil {
+		o.logger.Error("get balance failed", zap.String("asset", asset), zap.Error(err))
+		return decimal.Zero, err
+	}
+
+	for _, account := range accounts {
+		for _, d := range account.Details {
+			if strings.EqualFold(d.Ccy, asset) {
+				balance, err := decimal.NewFromString(d.AvailBal)
+				if err != nil {
+					o.logger.Error("parse balance failed", zap.String("balance", d.AvailBal), zap.Error(err))
+					return decimal.Zero, errs.Wrap(errs.ErrInternal, err)
+				}
+				return balance, nil
+			}
+		}
+	}
+	return decimal.Zero, errs.New(errs.ErrAssetNotFound, "asset %s not found", asset)
+}
+
+func (o *OKXExchange) GetBalances(ctx context.Context) ([]models.Balance, error) {
+	var accounts []struct {
+		Details []struct {
+			Ccy       string `json:"ccy"`
+			AvailBal  string `json:"availBal"`
+			FrozenBal string `json:"frozenBal"`
+		} `json:"details"`
+	}
+	if err := o.call(ctx, http.MethodGet, "/api/v5/account/balance", nil, nil, &accounts, nil); err != nil {
+		o.logger.Error("get balances failed", zap.Error(err))
+		return nil, err
+	}
+
+	var p decimals
+	var balances []models.Balance
+	for _, account := range accounts {
+		for _, d := range account.Details {
+			balances = appendBalance(balances, d.Ccy, p.parse(d.AvailBal), p.parse(d.FrozenBal))
+		}
+	}
+	if p.err != nil {
+		o.logger.Error("parse balances failed", zap.Error(p.err))
+		return nil, p.err
+	}
+	return balances, nil
+}
+
+func (o *OKXExchange) GetMarkets(ctx context.Context) ([]models.Market, error) {
+	params := url.Values{"instType": {"SPOT"}}
+
+	var items []models.OKXInstrumentResponse
+	if err := o.call(ctx, http.MethodGet, "/api/v5/public/instruments", params, nil, &items, errs.ErrExchangeUnavailable); err != nil {
+		o.logger.Error("get markets failed", zap.Error(err))
+		return nil, err
+	}
+
+	var p decimals
+	markets := make([]models.Market, 0, len(items))
+	for _, m := range items {
+		markets = append(markets, models.Market{
+			Symbol:      models.Symbol{Base: m.BaseCcy, Quote: m.QuoteCcy},
+			Tradable:    m.State == "live",
+			TickSize:    p.parse(m.TickSz),
+			StepSize:    p.parse(m.LotSz),
+			MinQuantity: p.parse(m.MinSz),
+			MaxQuantity: p.parse(m.MaxLmtSz),
+		})
+	}
+	if p.err != nil {
+		o.logger.Error("parse markets failed", zap.Error(p.err))
+		return nil, p.err
+	}
+	return markets, nil
+}
+
+// call sends a request and decodes the data field of OKX's
+// {"code", "msg", "data"} envelope into result.
+func (o *OKXExchange) call(ctx context.Context, method, path string, params url.Values, payload any, result any, notFound *errs.Kind) error {
+	endpoint := o.baseURL + path
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+	var body []byte
+	if payload != nil {
+		var err error
+		if body, err = json.Marshal(payload); err != nil {
+			return errs.Wrap(errs.ErrInternal, err)
+		}
+	}
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+	if o.simulated {
+		headers["x-simulated-trading"] = "1"
+	}
+
+	respBody, status, err := o.client.Do(ctx, method, endpoint, body, headers, o.signer)
+	var envelope struct {
+		Code string          `json:"code"`
+		Msg  string          `json:"msg"`
+		Data json.RawMessage `json:"data"`
+	}
+	if jsonErr := json.Unmarshal(respBody, &envelope); jsonErr != nil || envelope.Code == "" {
+		if err != nil || status < 200 || status >= 300 {
+			return httpError(path, status, respBody, err, notFound)
+		}
+		return errs.Wrap(errs.ErrInternal, fmt.Errorf("%s: unexpected response", path))
+	}
+	if envelope.Code != "0" {
+		// Code 1 only says the operation failed; the reason is per item.
+		var acks []okxOrderAck
+		if json.Unmarshal(envelope.Data, &acks) == nil {
+			for _, ack := range acks {
+				if err := ack.err(path, notFound); err != nil {
+					return err
+				}
+			}
+		}
+		return okxError(path, envelope.Code, envelope.Msg, notFound)
+	}
+	if err := json.Unmarshal(envelope.Data, result); err != nil {
+		return errs.Wrap(errs.ErrInternal, err)
+	}
+	return nil
+}
+
+// okxOrderAck is the per-order result of trade endpoints, which report
+// failures in sCode even when the envelope code is 0.
+type okxOrderAck struct {
+	OrdID string `json:"ordId"`
+	SCode string `json:"sCode"`
+	SMsg  string `json:"sMsg"`
+}
+
+func (a okxOrderAck) err(op string, notFound *errs.Kind) error {
+	if a.SCode == "" || a.SCode == "0" {
+		return nil
+	}
+	return okxError(op, a.SCode, a.SMsg, notFound)
+}
+
+// convertOKXOrder maps an order. Market orders carry no price, and the
+// average price stays empty until the order fills.
+func convertOKXOrder(o models.OKXOrderResponse) (models.Order, error) {
+	var p decimals
+	return models.Order{
+		OrderID:        o.OrdID,
+		Symbol:         splitSymbol(o.InstID, "-"),
+		Side:           strings.ToLower(o.Side),
+		Type:           strings.ToLower(o.OrdType),
+		Status:         okxOrderStatus(o.State),
+		Price:          p.optional(o.Px),
+		Quantity:       p.parse(o.Sz),
+		FilledQuantity: p.parse(o.AccFillSz),
+		AvgFillPrice:   p.optional(o.AvgPx),
+		CreatedAt:      parseMillis(o.CTime),
+		UpdatedAt:      parseMillis(o.UTime),
+	}, p.err
+}
+
+func okxSymbol(symbol models.Symbol) string {
+	return symbol.Join("-")
+}
+
+func okxOrderStatus(state string) models.OrderStatus {
+	switch state {
+	case "partially_filled":
+		return models.OrderStatusPartiallyFilled
+	case "filled":
+		return models.OrderStatusFilled
+	case "canceled", "mmp_canceled":
+		return models.OrderStatusCanceled
+	}
+	return models.OrderStatusNew
+}
+
+func parseMillis(value string) time.Time {
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
+
+func okxError(op, code, msg string, notFound *errs.Kind) error {
+	kind := errs.ErrInvalidRequest
+	switch {
+	case code == "50011" || code == "50061":
+		kind = errs.ErrRateLimited
+	case code == "50001" || code == "50013":
+		kind = errs.ErrExchangeUnavailable
+	case strings.HasPrefix(code, "501"):
+		kind = errs.ErrAuthFailed
+	case code == "51008" || code == "51131":
+		kind = errs.ErrInsufficientFunds
+	case code == "51001":
+		// Instrument ID does not exist, whatever the call was looking for.
+		kind = errs.ErrSymbolNotFound
+	case code == "51603":
+		kind = notFound
+	}
+	return errs.New(kind, "okx %s: code=%s, msg=%s", op, code, msg)
+}
@@ -0,0 +1,95 @@
+package exchange
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"eyeOne/internal/errs"
+	"eyeOne/models"
+)
+
+// pageOrders sorts orders newest first and returns the requested page.
+// It is used by adapters whose venue cannot paginate natively.
+func pageOrders(orders []models.Order, page, limit int) []models.Order {
+	sort.SliceStable(orders, func(i, j int) bool {
+		return orders[i].CreatedAt.After(orders[j].CreatedAt)
+	})
+
+	start := (page - 1) * limit
+	if start >= len(orders) {
+		return []models.Order{}
+	}
+	end := start + limit
+	if end > len(orders) {
+		end = len(orders)
+	}
+	return orders[start:end]
+}
+
+func closedOrders(orders []models.Order) []models.Order {
+	result := make([]models.Order, 0, len(orders))
+	for _, o := range orders {
+		if !o.Status.IsOpen() {
+			result = append(result, o)
+		}
+	}
+	return result
+}
+
+func ordersOnSide(orders []models.Order, side string) []models.Order {
+	if side == "" {
+		return orders
+	}
+	result := make([]models.Order, 0, len(orders))
+	for _, o := range orders {
+		if strings.EqualFold(o.Side, side) {
+			result = append(result, o)
+		}
+	}
+	return result
+}
+
+// cancelEach cancels orders one at a time, for venues without a native
+// batch cancel or when a filter the venue cannot express is applied.
+func cancelEach(ctx context.Context, ex Exchange, orders []models.Order) []models.CancelResult {
+	results := make([]models.CancelResult, 0, len(orders))
+	for _, o := range orders {
+		results = append(results, cancelResult(o.OrderID, o.Symbol, ex.CancelOrder(ctx, o.Symbol, o.OrderID)))
+	}
+	return results
+}
+
+func cancelAllOpenOrders(ctx context.Context, ex Exchange, symbol models.Symbol, side string) ([]models.CancelResult, error) {
+	orders, err := ex.ListOpenOrders(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return cancelEach(ctx, ex, ordersOnSide(orders, side)), nil
+}
+
+func cancelResult(orderID string, symbol models.Symbol, err error) models.CancelResult {
+	result := models.CancelResult{
+		OrderID:  orderID,
+		Symbol:   symbol,
+		Canceled: err == nil,
+	}
+	if err != nil {
+		result.Error = err.Error()
+		result.ErrorCode = errs.Code(err)
+	}
+	return result
+}
+
+func distinctSymbols(orders []models.Order) []models.Symbol {
+	seen := make(map[models.Symbol]struct{})
+	symbols := make([]models.Symbol, 0)
+	for _, o := range orders {
+		if _, ok := seen[o.Symbol]; ok {
+			continue
+		}
+		seen[o.Symbol] = struct{}{}
+		symbols = append(symbols, o.Symbol)
+	}
+	return symbols
+}
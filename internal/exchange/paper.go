@@ -0,0 +1,593 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"eyeOne/config"
+	"eyeOne/internal/errs"
+	"eyeOne/models"
+)
+
+// Resetter is implemented by simulated exchanges whose state can be wiped.
+type Resetter interface {
+	Reset()
+}
+
+type paperBalance struct {
+	free   decimal.Decimal
+	locked decimal.Decimal
+}
+
+// PaperExchange simulates trading with virtual balances. Limit orders rest
+// in an in-memory book with price-time priority; incoming orders trade
+// against it and against a reference book taken from a fixture file or a
+// real adapter. Liquidity taken from a reference level stays used until the
+// venue shows that level with a new quantity, so repeated orders walk the
+// book. Resting orders only fill against later paper orders.
+type PaperExchange struct {
+	reference Exchange
+	fixture   map[models.Symbol]models.OrderBook
+	makerFee  decimal.Decimal
+	takerFee  decimal.Decimal
+	initial   map[string]decimal.Decimal
+	logger    *zap.Logger
+	now       func() time.Time
+
+	mu       sync.Mutex
+	balances map[string]*paperBalance
+	books    map[models.Symbol]*paperBook
+	orders   map[string]*paperOrder
+	fills    []models.Fill
+	orderSeq int64
+	tradeSeq int64
+}
+
+func NewPaperExchange(reference Exchange, logger *zap.Logger, cfg *config.Config) (*PaperExchange, error) {
+	initial, err := parsePaperBalances(cfg.PaperBalances)
+	if err != nil {
+		return nil, err
+	}
+	makerFee, err := decimal.NewFromString(cfg.PaperMakerFee)
+	if err != nil {
+		return nil, fmt.Errorf("invalid paper maker fee %q: %w", cfg.PaperMakerFee, err)
+	}
+	takerFee, err := decimal.NewFromString(cfg.PaperTakerFee)
+	if err != nil {
+		return nil, fmt.Errorf("invalid paper taker fee %q: %w", cfg.PaperTakerFee, err)
+	}
+
+	fixture := make(map[models.Symbol]models.OrderBook)
+	if cfg.PaperFixture != "" {
+		if fixture, err = loadPaperFixture(cfg.PaperFixture); err != nil {
+			return nil, err
+		}
+	}
+	if reference == nil && len(fixture) == 0 {
+		logger.Warn("Paper exchange has no reference book; only paper orders can match")
+	}
+
+	p := &PaperExchange{
+		reference: reference,
+		fixture:   fixture,
+		makerFee:  makerFee,
+		takerFee:  takerFee,
+		initial:   initial,
+		logger:    logger,
+		now:       time.Now,
+	}
+	p.Reset()
+	return p, nil
+}
+
+// Unsupported reports candles when there is no reference exchange to take
+// them from.
+func (p *PaperExchange) Unsupported() []string {
+	if p.reference == nil {
+		return []string{"candles"}
+	}
+	return nil
+}
+
+// Reset restores the configured balances and drops all orders and fills.
+func (p *PaperExchange) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.balances = make(map[string]*paperBalance, len(p.initial))
+	for asset, amount := range p.initial {
+		p.balances[asset] = &paperBalance{free: amount}
+	}
+	p.books = make(map[models.Symbol]*paperBook)
+	p.orders = make(map[string]*paperOrder)
+	p.fills = nil
+	p.orderSeq, p.tradeSeq = 0, 0
+	p.logger.Info("Paper exchange reset")
+}
+
+func (p *PaperExchange) CreateOrder(ctx context.Context, symbol models.Symbol, side, orderType string, quantity, price decimal.Decimal) (string, error) {
+	side, orderType = strings.ToLower(side), strings.ToLower(orderType)
+	if side != "buy" && side != "sell" {
+		return "", errs.New(errs.ErrInvalidRequest, "invalid side %q", side)
+	}
+	if orderType != "limit" && orderType != "market" {
+		return "", errs.New(errs.ErrInvalidRequest, "paper exchange supports limit and market orders, got %q", orderType)
+	}
+	if !quantity.IsPositive() || (orderType == "limit" && !price.IsPositive()) {
+		return "", errs.New(errs.ErrInvalidRequest, "quantity and limit price must be positive")
+	}
+	if orderType == "market" {
+		price = decimal.Zero
+	}
+
+	ref, err := p.referenceBook(ctx, symbol, 0)
+	if err != nil {
+		if orderType == "market" {
+			return "", err
+		}
+		p.logger.Warn("No reference book, limit order can only match paper orders", zap.String("symbol", symbol.String()), zap.Error(err))
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	p.orderSeq++
+	taker := &paperOrder{
+		seq: p.orderSeq,
+		order: models.Order{
+			OrderID:   fmt.Sprintf("paper-%d", p.orderSeq),
+			Symbol:    symbol,
+			Side:      side,
+			Type:      orderType,
+			Status:    models.OrderStatusNew,
+			Price:     price,
+			Quantity:  quantity,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	}
+
+	levels := ref.Bids
+	if taker.isBuy() {
+		levels = ref.Asks
+	}
+	book := p.book(symbol)
+	matches := book.match(taker, book.untaken(levels, !taker.isBuy()))
+	if orderType == "market" && len(matches) == 0 {
+		return "", errs.New(errs.ErrInvalidRequest, "no liquidity for market order on %s", symbol)
+	}
+
+	asset, need := p.reservation(taker, matches)
+	balance := p.balance(asset)
+	if balance.free.LessThan(need) {
+		return "", errs.New(errs.ErrInsufficientFunds, "insufficient %s balance: need %s, have %s", asset, need, balance.free)
+	}
+	balance.free = balance.free.Sub(need)
+	balance.locked = balance.locked.Add(need)
+	taker.locked = need
+	if err == nil {
+		book.take(levels, !taker.isBuy(), matches)
+	}
+
+	for _, m := range matches {
+		p.execute(taker, m.price, m.quantity, models.LiquidityTaker)
+		if m.resting != nil {
+			p.execute(m.resting, m.price, m.quantity, models.LiquidityMaker)
+			if !m.resting.remaining().IsPositive() {
+				book.remove(m.resting)
+			}
+		}
+	}
+
+	if taker.remaining().IsPositive() {
+		if orderType == "market" {
+			// Market orders never rest; whatever the book could not fill expires.
+			taker.order.Status = models.OrderStatusCanceled
+		} else {
+			book.insert(taker)
+		}
+	}
+	p.release(taker)
+	p.orders[taker.order.OrderID] = taker
+
+	p.logger.Info("Paper order created",
+		zap.String("orderId", taker.order.OrderID),
+		zap.String("symbol", symbol.String()),
+		zap.String("status", string(taker.order.Status)),
+	)
+	return taker.order.OrderID, nil
+}
+
+// reservation is the amount an order locks up front: quote at the limit
+// price (or the planned cost of a market buy) for buys, base for sells.
+func (p *PaperExchange) reservation(o *paperOrder, matches []paperMatch) (string, decimal.Decimal) {
+	if !o.isBuy() {
+		if o.order.Type == "market" {
+			filled := decimal.Zero
+			for _, m := range matches {
+				filled = filled.Add(m.quantity)
+			}
+			return o.order.Symbol.Base, filled
+		}
+		return o.order.Symbol.Base, o.order.Quantity
+	}
+	if o.order.Type == "market" {
+		cost := decimal.Zero
+		for _, m := range matches {
+			cost = cost.Add(m.price.Mul(m.quantity))
+		}
+		return o.order.Symbol.Quote, cost
+	}
+	return o.order.Symbol.Quote, o.order.Quantity.Mul(o.order.Price)
+}
+
+// execute settles one side of a trade. Fees are taken from the asset
+// received.
+func (p *PaperExchange) execute(o *paperOrder, price, quantity decimal.Decimal, liq models.Liquidity) {
+	base, quote := p.balance(o.order.Symbol.Base), p.balance(o.order.Symbol.Quote)
+	feeRate := p.takerFee
+	if liq == models.LiquidityMaker {
+		feeRate = p.makerFee
+	}
+	cost := price.Mul(quantity)
+
+	var fee decimal.Decimal
+	var feeAsset string
+	if o.isBuy() {
+		reserved := cost
+		if o.order.Type == "limit" {
+			reserved = o.order.Price.Mul(quantity)
+		}
+		quote.locked = quote.locked.Sub(reserved)
+		quote.free = quote.free.Add(reserved.Sub(cost))
+		o.locked = o.locked.Sub(reserved)
+		fee, feeAsset = quantity.Mul(feeRate), o.order.Symbol.Base
+		base.free = base.free.Add(quantity.Sub(fee))
+	} else {
+		base.locked = base.locked.Sub(quantity)
+		o.locked = o.locked.Sub(quantity)
+		fee, feeAsset = cost.Mul(feeRate), o.order.Symbol.Quote
+		quote.free = quote.free.Add(cost.Sub(fee))
+	}
+
+	filled := o.order.FilledQuantity
+	o.order.AvgFillPrice = o.order.AvgFillPrice.Mul(filled).Add(cost).Div(filled.Add(quantity))
+	o.order.FilledQuantity = filled.Add(quantity)
+	o.order.Status = models.OrderStatusPartiallyFilled
+	if !o.remaining().IsPositive() {
+		o.order.Status = models.OrderStatusFilled
+	}
+	now := p.now()
+	o.order.UpdatedAt = now
+
+	if liq == models.LiquidityTaker {
+		p.tradeSeq++
+	}
+	p.fills = append(p.fills, models.Fill{
+		TradeID:   fmt.Sprintf("paper-t%d", p.tradeSeq),
+		OrderID:   o.order.OrderID,
+		Symbol:    o.order.Symbol,
+		Side:      o.order.Side,
+		Price:     price,
+		Quantity:  quantity,
+		Fee:       fee,
+		FeeAsset:  feeAsset,
+		Liquidity: liq,
+		Timestamp: now,
+	})
+	p.release(o)
+}
+
+// release returns what a finished order still has reserved.
+func (p *PaperExchange) release(o *paperOrder) {
+	if o.order.Status.IsOpen() || !o.locked.IsPositive() {
+		return
+	}
+	asset := o.order.Symbol.Base
+	if o.isBuy() {
+		asset = o.order.Symbol.Quote
+	}
+	balance := p.balance(asset)
+	balance.locked = balance.locked.Sub(o.locked)
+	balance.free = balance.free.Add(o.locked)
+	o.locked = decimal.Zero
+}
+
+func (p *PaperExchange) CancelOrder(ctx context.Context, symbol models.Symbol, orderID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	o, ok := p.orders[orderID]
+	if !ok {
+		return errs.New(errs.ErrOrderNotFound, "order %s not found", orderID)
+	}
+	if !o.order.Status.IsOpen() {
+		return errs.New(errs.ErrInvalidRequest, "order %s is already %s", orderID, o.order.Status)
+	}
+	p.book(o.order.Symbol).remove(o)
+	o.order.Status = models.OrderStatusCanceled
+	o.order.UpdatedAt = p.now()
+	p.release(o)
+	return nil
+}
+
+func (p *PaperExchange) GetOrder(ctx context.Context, symbol models.Symbol, orderID string) (models.Order, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	o, ok := p.orders[orderID]
+	if !ok {
+		return models.Order{}, errs.New(errs.ErrOrderNotFound, "order %s not found", orderID)
+	}
+	return o.order, nil
+}
+
+func (p *PaperExchange) ListOpenOrders(ctx context.Context, symbol models.Symbol) ([]models.Order, error) {
+	orders := p.filterOrders(func(o models.Order) bool {
+		return o.Status.IsOpen() && (symbol.IsZero() || o.Symbol == symbol)
+	})
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].CreatedAt.After(orders[j].CreatedAt)
+	})
+	return orders, nil
+}
+
+func (p *PaperExchange) ListOrderHistory(ctx context.Context, query models.OrderHistoryQuery) ([]models.Order, error) {
+	orders := p.filterOrders(func(o models.Order) bool {
+		if o.Status.IsOpen() || (!query.Symbol.IsZero() && o.Symbol != query.Symbol) {
+			return false
+		}
+		if !query.StartTime.IsZero() && o.CreatedAt.Before(query.StartTime) {
+			return false
+		}
+		return query.EndTime.IsZero() || !o.CreatedAt.After(query.EndTime)
+	})
+	return pageOrders(orders, query.Page, query.Limit), nil
+}
+
+func (p *PaperExchange) filterOrders(keep func(models.Order) bool) []models.Order {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	orders := make([]models.Order, 0)
+	for _, o := range p.orders {
+		if keep(o.order) {
+			orders = append(orders, o.order)
+		}
+	}
+	return orders
+}
+
+func (p *PaperExchange) CancelAllOrders(ctx context.Context, symbol models.Symbol, side string) ([]models.CancelResult, error) {
+	return cancelAllOpenOrders(ctx, p, symbol, side)
+}
+
+func (p *PaperExchange) GetFills(ctx context.Context, symbol models.Symbol, since time.Time) ([]models.Fill, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fills := make([]models.Fill, 0)
+	for _, f := range p.fills {
+		if f.Timestamp.Before(since) || (!symbol.IsZero() && f.Symbol != symbol) {
+			continue
+		}
+		fills = append(fills, f)
+	}
+	return fills, nil
+}
+
+func (p *PaperExchange) GetBalance(ctx context.Context, asset string) (decimal.Decimal, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if balance, ok := p.balances[strings.ToUpper(asset)]; ok {
+		return balance.free, nil
+	}
+	return decimal.Zero, errs.New(errs.ErrAssetNotFound, "asset %s not found", asset)
+}
+
+func (p *PaperExchange) GetBalances(ctx context.Context) ([]models.Balance, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var balances []models.Balance
+	for asset, balance := range p.balances {
+		balances = appendBalance(balances, asset, balance.free, balance.locked)
+	}
+	return balances, nil
+}
+
+// GetOrderBook returns the reference book, less what paper orders have
+// taken from it, with resting paper orders added.
+func (p *PaperExchange) GetOrderBook(ctx context.Context, symbol models.Symbol, limit int) (models.OrderBook, error) {
+	ref, refErr := p.referenceBook(ctx, symbol, limit)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	book, ok := p.books[symbol]
+	if refErr != nil && (!ok || len(book.bids)+len(book.asks) == 0) {
+		return models.OrderBook{}, refErr
+	}
+	if !ok {
+		return ref, nil
+	}
+	return models.OrderBook{
+		Bids:      mergeLevels(book.untaken(ref.Bids, true), book.depth(book.bids), true),
+		Asks:      mergeLevels(book.untaken(ref.Asks, false), book.depth(book.asks), false),
+		Sequence:  ref.Sequence,
+		Timestamp: ref.Timestamp,
+	}, nil
+}
+
+// GetTicker passes through to the reference exchange. Fixture symbols get
+// a ticker built from the book and the last paper trade.
+func (p *PaperExchange) GetTicker(ctx context.Context, symbol models.Symbol) (models.Ticker, error) {
+	if _, ok := p.fixture[symbol]; !ok && p.reference != nil {
+		return p.reference.GetTicker(ctx, symbol)
+	}
+
+	book, err := p.GetOrderBook(ctx, symbol, 1)
+	if err != nil {
+		return models.Ticker{}, err
+	}
+	ticker := models.Ticker{Symbol: symbol, Timestamp: p.now()}
+	if len(book.Bids) > 0 {
+		ticker.BidPrice = book.Bids[0].Price
+	}
+	if len(book.Asks) > 0 {
+		ticker.AskPrice = book.Asks[0].Price
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i := len(p.fills) - 1; i >= 0; i-- {
+		if p.fills[i].Symbol == symbol {
+			ticker.LastPrice = p.fills[i].Price
+			break
+		}
+	}
+	return ticker, nil
+}
+
+func (p *PaperExchange) GetCandles(ctx context.Context, symbol models.Symbol, interval models.CandleInterval, from, to time.Time, limit int) ([]models.Candle, error) {
+	if p.reference == nil {
+		return nil, errs.New(errs.ErrNotSupported, "paper exchange has no reference for candles")
+	}
+	return p.reference.GetCandles(ctx, symbol, interval, from, to, limit)
+}
+
+// GetRecentTrades is the reference venue's tape for symbols without a
+// fixture book, and otherwise the paper account's own taker fills.
+func (p *PaperExchange) GetRecentTrades(ctx context.Context, symbol models.Symbol, limit int) ([]models.Trade, error) {
+	if _, ok := p.fixture[symbol]; !ok && p.reference != nil {
+		return p.reference.GetRecentTrades(ctx, symbol, limit)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	trades := make([]models.Trade, 0)
+	for i := len(p.fills) - 1; i >= 0 && len(trades) < limit; i-- {
+		f := p.fills[i]
+		if f.Symbol != symbol || f.Liquidity != models.LiquidityTaker {
+			continue
+		}
+		trades = append(trades, models.Trade{
+			TradeID:   f.TradeID,
+			Price:     f.Price,
+			Quantity:  f.Quantity,
+			Side:      f.Side,
+			Timestamp: f.Timestamp,
+		})
+	}
+	return trades, nil
+}
+
+func (p *PaperExchange) GetMarkets(ctx context.Context) ([]models.Market, error) {
+	var markets []models.Market
+	if p.reference != nil {
+		var err error
+		if markets, err = p.reference.GetMarkets(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	listed := make(map[models.Symbol]bool, len(markets))
+	for _, m := range markets {
+		listed[m.Symbol] = true
+	}
+	for symbol := range p.fixture {
+		if !listed[symbol] {
+			markets = append(markets, models.Market{Symbol: symbol, Tradable: true})
+		}
+	}
+	return markets, nil
+}
+
+func (p *PaperExchange) referenceBook(ctx context.Context, symbol models.Symbol, limit int) (models.OrderBook, error) {
+	if book, ok := p.fixture[symbol]; ok {
+		return book, nil
+	}
+	if p.reference == nil {
+		return models.OrderBook{}, errs.New(errs.ErrSymbolNotFound, "no reference order book for %s", symbol)
+	}
+	return p.reference.GetOrderBook(ctx, symbol, limit)
+}
+
+func (p *PaperExchange) book(symbol models.Symbol) *paperBook {
+	book, ok := p.books[symbol]
+	if !ok {
+		book = &paperBook{}
+		p.books[symbol] = book
+	}
+	return book
+}
+
+func (p *PaperExchange) balance(asset string) *paperBalance {
+	balance, ok := p.balances[asset]
+	if !ok {
+		balance = &paperBalance{}
+		p.balances[asset] = balance
+	}
+	return balance
+}
+
+// parsePaperBalances reads ASSET:AMOUNT pairs such as usdt:10000.
+func parsePaperBalances(items []string) (map[string]decimal.Decimal, error) {
+	balances := make(map[string]decimal.Decimal, len(items))
+	for _, item := range items {
+		asset, amount, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid paper balance %q, expected ASSET:AMOUNT", item)
+		}
+		value, err := decimal.NewFromString(strings.TrimSpace(amount))
+		if err != nil || value.IsNegative() {
+			return nil, fmt.Errorf("invalid paper balance %q", item)
+		}
+		balances[strings.ToUpper(strings.TrimSpace(asset))] = value
+	}
+	return balances, nil
+}
+
+// loadPaperFixture reads static order books keyed by symbol, e.g.
+// {"BTC/USDT": {"bids": [["64000", "1.5"]], "asks": [["64010", "2"]]}}.
+func loadPaperFixture(path string) (map[models.Symbol]models.OrderBook, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read paper fixture: %w", err)
+	}
+	var raw map[string]struct {
+		Bids [][]string `json:"bids"`
+		Asks [][]string `json:"asks"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse paper fixture: %w", err)
+	}
+
+	books := make(map[models.Symbol]models.OrderBook, len(raw))
+	for name, book := range raw {
+		symbol, err := models.ParseSymbol(name)
+		if err != nil {
+			return nil, fmt.Errorf("paper fixture: %w", err)
+		}
+		var p decimals
+		bids, asks := convertEntries(&p, book.Bids), convertEntries(&p, book.Asks)
+		if p.err != nil {
+			return nil, fmt.Errorf("paper fixture %s: %w", name, p.err)
+		}
+		sort.Slice(bids, func(i, j int) bool { return bids[i].Price.GreaterThan(bids[j].Price) })
+		sort.Slice(asks, func(i, j int) bool { return asks[i].Price.LessThan(asks[j].Price) })
+		books[symbol] = models.OrderBook{Bids: bids, Asks: asks}
+	}
+	return books, nil
+}
@@ -0,0 +1,254 @@
+package exchange
+
+import (
+	"sort"
+
+	"github.com/shopspring/decimal"
+
+	"eyeOne/models"
+)
+
+// paperOrder is an order inside the paper exchange. locked is what is
+// still reserved for it: quote for buys, base for sells.
+type paperOrder struct {
+	order  models.Order
+	seq    int64
+	locked decimal.Decimal
+}
+
+func (o *paperOrder) remaining() decimal.Decimal {
+	return o.order.Quantity.Sub(o.order.FilledQuantity)
+}
+
+func (o *paperOrder) isBuy() bool {
+	return o.order.Side == "buy"
+}
+
+// paperBook holds resting limit orders for one symbol in price-time
+// priority: best price first, then oldest first. It also remembers how much
+// of each reference level paper orders have taken, keyed by price, so a
+// later order does not fill against the same liquidity again.
+type paperBook struct {
+	bids []*paperOrder
+	asks []*paperOrder
+
+	bidsTaken map[string]takenLevel
+	asksTaken map[string]takenLevel
+}
+
+// takenLevel is how much of one reference level paper orders have used.
+// It holds while the venue still shows the level with the quantity it was
+// taken from; a different quantity means the book was refreshed there.
+type takenLevel struct {
+	shown decimal.Decimal
+	taken decimal.Decimal
+}
+
+func (b *paperBook) insert(o *paperOrder) {
+	side := &b.asks
+	better := func(a, c *paperOrder) bool { return a.order.Price.LessThan(c.order.Price) }
+	if o.isBuy() {
+		side = &b.bids
+		better = func(a, c *paperOrder) bool { return a.order.Price.GreaterThan(c.order.Price) }
+	}
+
+	levels := *side
+	i := sort.Search(len(levels), func(i int) bool {
+		return better(o, levels[i]) || (o.order.Price.Equal(levels[i].order.Price) && o.seq < levels[i].seq)
+	})
+	levels = append(levels, nil)
+	copy(levels[i+1:], levels[i:])
+	levels[i] = o
+	*side = levels
+}
+
+func (b *paperBook) remove(o *paperOrder) {
+	side := &b.asks
+	if o.isBuy() {
+		side = &b.bids
+	}
+	levels := *side
+	for i, resting := range levels {
+		if resting == o {
+			*side = append(levels[:i], levels[i+1:]...)
+			return
+		}
+	}
+}
+
+// opposite returns the resting orders an incoming order can trade with.
+func (b *paperBook) opposite(o *paperOrder) []*paperOrder {
+	if o.isBuy() {
+		return b.asks
+	}
+	return b.bids
+}
+
+// untaken returns reference levels less what paper orders have already
+// taken from them. Levels taken in full are left out.
+func (b *paperBook) untaken(levels []models.OrderBookEntry, bids bool) []models.OrderBookEntry {
+	taken := b.asksTaken
+	if bids {
+		taken = b.bidsTaken
+	}
+	if len(taken) == 0 {
+		return levels
+	}
+
+	result := make([]models.OrderBookEntry, 0, len(levels))
+	for _, level := range levels {
+		if t, ok := taken[level.Price.String()]; ok && t.shown.Equal(level.Quantity) {
+			level.Quantity = level.Quantity.Sub(t.taken)
+			if !level.Quantity.IsPositive() {
+				continue
+			}
+		}
+		result = append(result, level)
+	}
+	return result
+}
+
+// take records the reference liquidity used by matches. levels is the whole
+// side as the venue last showed it; records for levels that are gone or
+// show a new quantity are dropped.
+func (b *paperBook) take(levels []models.OrderBookEntry, bids bool, matches []paperMatch) {
+	old := &b.asksTaken
+	if bids {
+		old = &b.bidsTaken
+	}
+
+	taken := make(map[string]takenLevel, len(*old))
+	shown := make(map[string]decimal.Decimal, len(levels))
+	for _, level := range levels {
+		key := level.Price.String()
+		shown[key] = level.Quantity
+		if t, ok := (*old)[key]; ok && t.shown.Equal(level.Quantity) {
+			taken[key] = t
+		}
+	}
+	for _, m := range matches {
+		if m.resting != nil {
+			continue
+		}
+		key := m.price.String()
+		t := taken[key]
+		t.shown = shown[key]
+		t.taken = t.taken.Add(m.quantity)
+		taken[key] = t
+	}
+	*old = taken
+}
+
+// paperMatch is one planned execution. resting is nil when the liquidity
+// comes from the reference book.
+type paperMatch struct {
+	resting  *paperOrder
+	price    decimal.Decimal
+	quantity decimal.Decimal
+}
+
+// match plans how taker executes against the resting paper orders and the
+// reference book together, always taking the better price and preferring
+// resting orders on ties. Nothing is modified.
+func (b *paperBook) match(taker *paperOrder, reference []models.OrderBookEntry) []paperMatch {
+	resting := b.opposite(taker)
+	limit := taker.order.Type != "market"
+	crosses := func(price decimal.Decimal) bool {
+		if !limit {
+			return true
+		}
+		if taker.isBuy() {
+			return price.LessThanOrEqual(taker.order.Price)
+		}
+		return price.GreaterThanOrEqual(taker.order.Price)
+	}
+	better := func(a, c decimal.Decimal) bool {
+		if taker.isBuy() {
+			return a.LessThanOrEqual(c)
+		}
+		return a.GreaterThanOrEqual(c)
+	}
+
+	var (
+		matches  []paperMatch
+		left     = taker.remaining()
+		ri, li   int
+		levelQty decimal.Decimal
+	)
+	if len(reference) > 0 {
+		levelQty = reference[0].Quantity
+	}
+	for left.IsPositive() {
+		hasResting := ri < len(resting)
+		hasLevel := li < len(reference)
+		if !hasResting && !hasLevel {
+			break
+		}
+
+		if hasResting && (!hasLevel || better(resting[ri].order.Price, reference[li].Price)) {
+			o := resting[ri]
+			if !crosses(o.order.Price) {
+				break
+			}
+			qty := decimal.Min(left, o.remaining())
+			matches = append(matches, paperMatch{resting: o, price: o.order.Price, quantity: qty})
+			left = left.Sub(qty)
+			ri++
+			continue
+		}
+
+		level := reference[li]
+		if !crosses(level.Price) {
+			break
+		}
+		qty := decimal.Min(left, levelQty)
+		if qty.IsPositive() {
+			matches = append(matches, paperMatch{price: level.Price, quantity: qty})
+			left = left.Sub(qty)
+			levelQty = levelQty.Sub(qty)
+		}
+		if !levelQty.IsPositive() {
+			li++
+			if li < len(reference) {
+				levelQty = reference[li].Quantity
+			}
+		}
+	}
+	return matches
+}
+
+// depth aggregates resting orders into price levels.
+func (b *paperBook) depth(orders []*paperOrder) []models.OrderBookEntry {
+	var entries []models.OrderBookEntry
+	for _, o := range orders {
+		if n := len(entries); n > 0 && entries[n-1].Price.Equal(o.order.Price) {
+			entries[n-1].Quantity = entries[n-1].Quantity.Add(o.remaining())
+			continue
+		}
+		entries = append(entries, models.OrderBookEntry{Price: o.order.Price, Quantity: o.remaining()})
+	}
+	return entries
+}
+
+// mergeLevels combines two sides sorted best first into one.
+func mergeLevels(a, b []models.OrderBookEntry, bids bool) []models.OrderBookEntry {
+	byPrice := make(map[string]decimal.Decimal, len(a)+len(b))
+	prices := make(map[string]decimal.Decimal, len(a)+len(b))
+	for _, e := range append(append([]models.OrderBookEntry{}, a...), b...) {
+		key := e.Price.String()
+		byPrice[key] = byPrice[key].Add(e.Quantity)
+		prices[key] = e.Price
+	}
+
+	merged := make([]models.OrderBookEntry, 0, len(byPrice))
+	for key, qty := range byPrice {
+		merged = append(merged, models.OrderBookEntry{Price: prices[key], Quantity: qty})
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		if bids {
+			return merged[i].Price.GreaterThan(merged[j].Price)
+		}
+		return merged[i].Price.LessThan(merged[j].Price)
+	})
+	return merged
+}
@@ -0,0 +1,118 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"eyeOne/config"
+	"eyeOne/internal/errs"
+	"eyeOne/models"
+)
+
+// newPaperTestExchange starts a paper exchange on a two-level BTC/USDT
+// fixture book.
+func newPaperTestExchange(t *testing.T) *PaperExchange {
+	t.Helper()
+	fixture := filepath.Join(t.TempDir(), "books.json")
+	book := `{"BTC/USDT": {"bids": [["64000", "1"], ["63900", "2"]], "asks": [["64010", "1"], ["64100", "2"]]}}`
+	if err := os.WriteFile(fixture, []byte(book), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	p, err := NewPaperExchange(nil, zap.NewNop(), &config.Config{
+		PaperBalances: []string{"USDT:1000000", "BTC:10"},
+		PaperMakerFee: "0",
+		PaperTakerFee: "0",
+		PaperFixture:  fixture,
+	})
+	if err != nil {
+		t.Fatalf("NewPaperExchange: %v", err)
+	}
+	return p
+}
+
+func TestPaperGetBalanceUnknownAsset(t *testing.T) {
+	p := newPaperTestExchange(t)
+
+	if _, err := p.GetBalance(context.Background(), "ETH"); !errors.Is(err, errs.ErrAssetNotFound) {
+		t.Errorf("GetBalance(ETH) error = %v, want %v", err, errs.ErrAssetNotFound)
+	}
+}
+
+func TestPaperOrdersWalkTheReferenceBook(t *testing.T) {
+	p := newPaperTestExchange(t)
+	ctx := context.Background()
+	buy := func(quantity string) string {
+		t.Helper()
+		id, err := p.CreateOrder(ctx, btcUSDT, "buy", "market", decimal.RequireFromString(quantity), decimal.Zero)
+		if err != nil {
+			t.Fatalf("CreateOrder: %v", err)
+		}
+		return id
+	}
+
+	first := buy("0.6")
+	second := buy("0.6")
+
+	fills, err := p.GetFills(ctx, btcUSDT, time.Time{})
+	if err != nil {
+		t.Fatalf("GetFills: %v", err)
+	}
+	if len(fills) != 3 {
+		t.Fatalf("got %d fills, want 3", len(fills))
+	}
+	if fills[0].OrderID != first || fills[1].OrderID != second {
+		t.Fatalf("fills are for orders %s and %s, want %s and %s", fills[0].OrderID, fills[1].OrderID, first, second)
+	}
+	assertDecimal(t, "first fill price", fills[0].Price, "64010")
+	assertDecimal(t, "second order best level", fills[1].Quantity, "0.4")
+	assertDecimal(t, "second order next level", fills[2].Price, "64100")
+	assertDecimal(t, "second order next level quantity", fills[2].Quantity, "0.2")
+
+	order, err := p.GetOrder(ctx, btcUSDT, second)
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	assertDecimal(t, "average price", order.AvgFillPrice, "64040")
+
+	book, err := p.GetOrderBook(ctx, btcUSDT, 0)
+	if err != nil {
+		t.Fatalf("GetOrderBook: %v", err)
+	}
+	if len(book.Asks) != 1 {
+		t.Fatalf("got %d ask levels, want the exhausted best level left out", len(book.Asks))
+	}
+	assertDecimal(t, "remaining ask", book.Asks[0].Quantity, "1.8")
+	assertDecimal(t, "untouched bid", book.Bids[0].Quantity, "1")
+
+	p.Reset()
+	if book, _ = p.GetOrderBook(ctx, btcUSDT, 0); len(book.Asks) != 2 {
+		t.Errorf("got %d ask levels after reset, want 2", len(book.Asks))
+	}
+}
+
+func TestPaperBookRefreshRestoresLevel(t *testing.T) {
+	var b paperBook
+	shown := refLevels("64010", "1")
+	b.take(shown, false, []paperMatch{{price: decimal.RequireFromString("64010"), quantity: decimal.RequireFromString("0.75")}})
+
+	if got := b.untaken(shown, false); len(got) != 1 || !got[0].Quantity.Equal(decimal.RequireFromString("0.25")) {
+		t.Fatalf("untaken = %v, want 0.25 left at 64010", got)
+	}
+
+	refreshed := refLevels("64010", "3")
+	if got := b.untaken(refreshed, false); len(got) != 1 || !got[0].Quantity.Equal(decimal.RequireFromString("3")) {
+		t.Errorf("untaken after refresh = %v, want the new quantity in full", got)
+	}
+}
+
+func refLevels(price, quantity string) []models.OrderBookEntry {
+	return []models.OrderBookEntry{{Price: decimal.RequireFromString(price), Quantity: decimal.RequireFromString(quantity)}}
+}
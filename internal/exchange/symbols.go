@@ -0,0 +1,70 @@
+package exchange
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"eyeOne/models"
+)
+
+// quoteAssets are the quote currencies tried, longest first, when a
+// concatenated venue symbol such as BTCUSDT is not in the market list yet.
+var quoteAssets = sortedByLength([]string{
+	"USDT", "USDC", "FDUSD", "TUSD", "BUSD", "DAI", "USD", "EUR", "GBP", "TRY",
+	"BRL", "BTC", "ETH", "BNB", "IRT", "TMN", "RLS",
+})
+
+func sortedByLength(assets []string) []string {
+	sort.SliceStable(assets, func(i, j int) bool {
+		return len(assets[i]) > len(assets[j])
+	})
+	return assets
+}
+
+// symbolIndex translates venue symbols without a separator back into
+// canonical pairs. Adapters fill it from their market list.
+type symbolIndex struct {
+	mu      sync.RWMutex
+	symbols map[string]models.Symbol
+}
+
+func newSymbolIndex() *symbolIndex {
+	return &symbolIndex{symbols: make(map[string]models.Symbol)}
+}
+
+func (x *symbolIndex) store(native string, symbol models.Symbol) {
+	x.mu.Lock()
+	x.symbols[native] = symbol
+	x.mu.Unlock()
+}
+
+func (x *symbolIndex) lookup(native string) models.Symbol {
+	if symbol, ok := x.find(native); ok {
+		return symbol
+	}
+	return splitByQuote(native)
+}
+
+func (x *symbolIndex) find(native string) (models.Symbol, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	symbol, ok := x.symbols[native]
+	return symbol, ok
+}
+
+func splitByQuote(native string) models.Symbol {
+	native = strings.ToUpper(native)
+	for _, quote := range quoteAssets {
+		if len(native) > len(quote) && strings.HasSuffix(native, quote) {
+			return models.Symbol{Base: strings.TrimSuffix(native, quote), Quote: quote}
+		}
+	}
+	return models.Symbol{Base: native}
+}
+
+// splitSymbol parses venue symbols that use a separator, e.g. BTC-USDT.
+func splitSymbol(native, sep string) models.Symbol {
+	base, quote, _ := strings.Cut(strings.ToUpper(native), sep)
+	return models.Symbol{Base: base, Quote: quote}
+}
@@ -1,37 +1,506 @@
 package exchange
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 
+	"eyeOne/config"
+	"eyeOne/internal/errs"
+	"eyeOne/internal/httpclient"
 	"eyeOne/models"
 )
 
+const wallexMaxCandles = 1000
+
 type WallexExchange struct {
-	logger *zap.Logger
+	baseURL string
+	apiKey  string
+	client  *httpclient.Client
+	symbols *symbolIndex
+	logger  *zap.Logger
+}
+
+func NewWallexExchange(client *httpclient.Client, logger *zap.Logger, cfg *config.Config) (*WallexExchange, error) {
+	baseURL := "https://api.wallex.ir"
+	if cfg.WallexBaseURL != "" {
+		baseURL = strings.TrimSuffix(cfg.WallexBaseURL, "/")
+	} else if !cfg.WallexEnv.IsProduction() {
+		return nil, fmt.Errorf("wallex has no public %s; set WALLEX_BASE_URL to its address", cfg.WallexEnv)
+	}
+	logger.Info("Initialized Wallex client", zap.String("environment", string(cfg.WallexEnv)), zap.String("baseUrl", baseURL))
+	return &WallexExchange{
+		baseURL: baseURL,
+		apiKey:  cfg.WallexAPIKey,
+		client:  client,
+		symbols: newSymbolIndex(),
+		logger:  logger,
+	}, nil
+}
+
+// GetOrderBook returns Wallex's fixed-depth book; the venue takes no limit.
+func (w *WallexExchange) GetOrderBook(ctx context.Context, symbol models.Symbol, limit int) (models.OrderBook, error) {
+	endpoint := fmt.Sprintf("%s/v1/depth?symbol=%s", w.baseURL, url.QueryEscape(wallexSymbol(symbol)))
+	body, status, err := w.client.Get(ctx, endpoint, w.headers())
+
+	var res models.WallexOrderBookResponse
+	if err := wallexResult("order book", body, status, err, &res, errs.ErrSymbolNotFound); err != nil {
+		w.logger.Error("get order book failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return models.OrderBook{}, err
+	}
+
+	return models.OrderBook{
+		Bids: wallexEntries(res.Bid),
+		Asks: wallexEntries(res.Ask),
+	}, nil
+}
+
+// GetTicker reads the market statistics Wallex publishes with its market
+// list; there is no per-symbol ticker endpoint.
+func (w *WallexExchange) GetTicker(ctx context.Context, symbol models.Symbol) (models.Ticker, error) {
+	endpoint := fmt.Sprintf("%s/v1/markets", w.baseURL)
+	body, status, err := w.client.Get(ctx, endpoint, w.headers())
+
+	var res struct {
+		Symbols map[string]models.WallexMarketResponse `json:"symbols"`
+	}
+	if err := wallexResult("markets", body, status, err, &res, errs.ErrSymbolNotFound); err != nil {
+		w.logger.Error("get markets failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return models.Ticker{}, err
+	}
+
+	m, ok := res.Symbols[wallexSymbol(symbol)]
+	if !ok {
+		return models.Ticker{}, errs.New(errs.ErrSymbolNotFound, "no ticker for %s", symbol)
+	}
+	// Stats mix strings and numbers and use "-" for no value.
+	var stats map[string]any
+	dec := json.NewDecoder(bytes.NewReader(m.Stats))
+	dec.UseNumber()
+	if err := dec.Decode(&stats); err != nil {
+		w.logger.Error("unmarshal market stats failed", zap.Error(err))
+		return models.Ticker{}, errs.Wrap(errs.ErrInternal, err)
+	}
+	var p decimals
+	stat := func(key string) decimal.Decimal {
+		value, ok := stats[key]
+		if !ok || value == nil || value == "-" {
+			return decimal.Zero
+		}
+		return p.parse(fmt.Sprint(value))
+	}
+
+	ticker := models.Ticker{
+		Symbol:      symbol,
+		LastPrice:   stat("lastPrice"),
+		BidPrice:    stat("bidPrice"),
+		AskPrice:    stat("askPrice"),
+		HighPrice:   stat("24h_highPrice"),
+		LowPrice:    stat("24h_lowPrice"),
+		Volume:      stat("24h_volume"),
+		QuoteVolume: stat("24h_quoteVolume"),
+		Timestamp:   time.Now(),
+	}
+	changeFromPercent(&ticker, stat("24h_ch"))
+	if p.err != nil {
+		w.logger.Error("parse market stats failed", zap.Error(p.err))
+		return models.Ticker{}, p.err
+	}
+	return ticker, nil
 }
 
-func NewWallexExchange(logger *zap.Logger) *WallexExchange {
-	return &WallexExchange{logger: logger}
+func (w *WallexExchange) GetCandles(ctx context.Context, symbol models.Symbol, interval models.CandleInterval, from, to time.Time, limit int) ([]models.Candle, error) {
+	return pageCandles(interval, from, to, limit, wallexMaxCandles, func(start, end time.Time, size int) ([]models.Candle, error) {
+		params := url.Values{
+			"symbol":     {wallexSymbol(symbol)},
+			"resolution": {udfResolution(interval)},
+			"from":       {strconv.FormatInt(start.Unix(), 10)},
+			"to":         {strconv.FormatInt(end.Unix(), 10)},
+		}
+		endpoint := fmt.Sprintf("%s/v1/udf/history?%s", w.baseURL, params.Encode())
+		candles, err := getUDFHistory(ctx, w.client, endpoint, w.headers())
+		if err != nil {
+			w.logger.Error("get candles failed", zap.String("symbol", symbol.String()), zap.Error(err))
+		}
+		return candles, err
+	})
 }
 
-func (w *WallexExchange) CreateOrder(ctx context.Context, symbol, side, orderType string, quantity, price float64) (string, error) {
-	w.logger.Info("Wallex: creating order", zap.String("symbol", symbol))
-	return "mock-order-id-wallex", nil
+func (w *WallexExchange) GetRecentTrades(ctx context.Context, symbol models.Symbol, limit int) ([]models.Trade, error) {
+	endpoint := fmt.Sprintf("%s/v1/trades?symbol=%s", w.baseURL, url.QueryEscape(wallexSymbol(symbol)))
+	body, status, err := w.client.Get(ctx, endpoint, w.headers())
+
+	var res struct {
+		LatestTrades []models.WallexPublicTradeResponse `json:"latestTrades"`
+	}
+	if err := wallexResult("trades", body, status, err, &res, errs.ErrSymbolNotFound); err != nil {
+		w.logger.Error("get trades failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return nil, err
+	}
+
+	var p decimals
+	trades := make([]models.Trade, 0, len(res.LatestTrades))
+	for _, t := range res.LatestTrades {
+		side := "sell"
+		if t.IsBuyOrder {
+			side = "buy"
+		}
+		trades = append(trades, models.Trade{
+			Price:     p.parse(t.Price),
+			Quantity:  p.parse(t.Quantity),
+			Side:      side,
+			Timestamp: parseWallexTime(t.Timestamp),
+		})
+	}
+	if p.err != nil {
+		w.logger.Error("parse trades failed", zap.Error(p.err))
+		return nil, p.err
+	}
+	return trades, nil
 }
 
-func (w *WallexExchange) CancelOrder(ctx context.Context, symbol, orderID string) error {
-	w.logger.Info("Wallex: canceling order", zap.String("orderID", orderID))
+func (w *WallexExchange) CreateOrder(ctx context.Context, symbol models.Symbol, side, orderType string, quantity, price decimal.Decimal) (string, error) {
+	endpoint := fmt.Sprintf("%s/v1/account/orders", w.baseURL)
+	payload := map[string]interface{}{
+		"symbol":   wallexSymbol(symbol),
+		"type":     strings.ToUpper(orderType),
+		"side":     strings.ToUpper(side),
+		"quantity": quantity.String(),
+	}
+	if !strings.EqualFold(orderType, "market") {
+		payload["price"] = price.String()
+	}
+
+	body, status, err := w.client.PostJSON(ctx, endpoint, payload, w.headers())
+
+	var order models.WallexOrderResponse
+	if err := wallexResult("order creation", body, status, err, &order, errs.ErrSymbolNotFound); err != nil {
+		w.logger.Error("order creation failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return "", err
+	}
+	return order.ClientOrderID, nil
+}
+
+func (w *WallexExchange) CancelOrder(ctx context.Context, symbol models.Symbol, orderID string) error {
+	endpoint := fmt.Sprintf("%s/v1/account/orders?clientOrderId=%s", w.baseURL, url.QueryEscape(orderID))
+	body, status, err := w.client.Delete(ctx, endpoint, w.headers())
+	if err := wallexResult("cancel order", body, status, err, nil, errs.ErrOrderNotFound); err != nil {
+		w.logger.Error("cancel order failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return err
+	}
 	return nil
 }
 
-func (w *WallexExchange) GetBalance(ctx context.Context, asset string) (float64, error) {
-	w.logger.Info("Wallex: getting balance", zap.String("asset", asset))
-	return 100.0, nil
+func (w *WallexExchange) GetOrder(ctx context.Context, symbol models.Symbol, orderID string) (models.Order, error) {
+	endpoint := fmt.Sprintf("%s/v1/account/orders/%s", w.baseURL, url.PathEscape(orderID))
+	body, status, err := w.client.Get(ctx, endpoint, w.headers())
+
+	var order models.WallexOrderResponse
+	if err := wallexResult("get order", body, status, err, &order, errs.ErrOrderNotFound); err != nil {
+		w.logger.Error("get order failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return models.Order{}, err
+	}
+	result, err := w.convertOrder(order)
+	if err != nil {
+		w.logger.Error("parse order failed", zap.Error(err))
+		return models.Order{}, err
+	}
+	return result, nil
+}
+
+func (w *WallexExchange) ListOpenOrders(ctx context.Context, symbol models.Symbol) ([]models.Order, error) {
+	endpoint := fmt.Sprintf("%s/v1/account/openOrders", w.baseURL)
+	if !symbol.IsZero() {
+		endpoint += "?symbol=" + url.QueryEscape(wallexSymbol(symbol))
+	}
+	body, status, err := w.client.Get(ctx, endpoint, w.headers())
+
+	var res struct {
+		Orders []models.WallexOrderResponse `json:"orders"`
+	}
+	if err := wallexResult("list open orders", body, status, err, &res, errs.ErrSymbolNotFound); err != nil {
+		w.logger.Error("list open orders failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return nil, err
+	}
+
+	orders := make([]models.Order, 0, len(res.Orders))
+	for _, o := range res.Orders {
+		order, err := w.convertOrder(o)
+		if err != nil {
+			w.logger.Error("parse order failed", zap.Error(err))
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+// ListOrderHistory is not available: Wallex's API lists open orders and
+// looks up single orders, but has no endpoint for closed ones.
+func (w *WallexExchange) ListOrderHistory(ctx context.Context, query models.OrderHistoryQuery) ([]models.Order, error) {
+	return nil, errs.New(errs.ErrNotSupported, "wallex does not expose closed order history")
+}
+
+func (w *WallexExchange) Unsupported() []string {
+	return []string{"orderHistory"}
+}
+
+func (w *WallexExchange) CancelAllOrders(ctx context.Context, symbol models.Symbol, side string) ([]models.CancelResult, error) {
+	return cancelAllOpenOrders(ctx, w, symbol, side)
+}
+
+func (w *WallexExchange) GetFills(ctx context.Context, symbol models.Symbol, since time.Time) ([]models.Fill, error) {
+	endpoint := fmt.Sprintf("%s/v1/account/trades", w.baseURL)
+	if !symbol.IsZero() {
+		endpoint += "?symbol=" + url.QueryEscape(wallexSymbol(symbol))
+	}
+	body, status, err := w.client.Get(ctx, endpoint, w.headers())
+
+	var res struct {
+		Trades []models.WallexTradeResponse `json:"AccountLatestTrades"`
+	}
+	if err := wallexResult("list fills", body, status, err, &res, errs.ErrSymbolNotFound); err != nil {
+		w.logger.Error("list fills failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return nil, err
+	}
+
+	// The trades endpoint has no time filter, so older fills are dropped here.
+	var p decimals
+	fills := make([]models.Fill, 0, len(res.Trades))
+	for _, t := range res.Trades {
+		timestamp := parseWallexTime(t.Timestamp)
+		if timestamp.Before(since) {
+			continue
+		}
+		side := "sell"
+		if t.IsBuyer {
+			side = "buy"
+		}
+		fills = append(fills, models.Fill{
+			TradeID:   t.ID,
+			OrderID:   t.ClientOrderID,
+			Symbol:    w.symbols.lookup(t.Symbol),
+			Side:      side,
+			Price:     p.parse(t.Price),
+			Quantity:  p.parse(t.Quantity),
+			Fee:       p.parse(t.Fee),
+			FeeAsset:  t.FeeAsset,
+			Liquidity: liquidity(t.IsMaker),
+			Timestamp: timestamp,
+		})
+	}
+	if p.err != nil {
+		w.logger.Error("parse fills failed", zap.Error(p.err))
+		return nil, p.err
+	}
+	return fills, nil
+}
+
+func (w *WallexExchange) GetBalance(ctx context.Context, asset string) (decimal.Decimal, error) {
+	balances, err := w.balances(ctx)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	balance, ok := balances[strings.ToUpper(asset)]
+	if !ok {
+		return decimal.Zero, errs.New(errs.ErrAssetNotFound, "asset %s not found", asset)
+	}
+	var p decimals
+	value := p.parse(balance.Value).Sub(p.parse(balance.Locked))
+	if p.err != nil {
+		w.logger.Error("parse balance failed", zap.String("balance", balance.Value), zap.Error(p.err))
+		return decimal.Zero, p.err
+	}
+	return value, nil
+}
+
+func (w *WallexExchange) GetBalances(ctx context.Context) ([]models.Balance, error) {
+	res, err := w.balances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var p decimals
+	var balances []models.Balance
+	for asset, balance := range res {
+		locked := p.parse(balance.Locked)
+		balances = appendBalance(balances, asset, p.parse(balance.Value).Sub(locked), locked)
+	}
+	if p.err != nil {
+		w.logger.Error("parse balances failed", zap.Error(p.err))
+		return nil, p.err
+	}
+	return balances, nil
+}
+
+func (w *WallexExchange) balances(ctx context.Context) (map[string]models.WallexBalanceResponse, error) {
+	endpoint := fmt.Sprintf("%s/v1/account/balances", w.baseURL)
+	body, status, err := w.client.Get(ctx, endpoint, w.headers())
+
+	var res struct {
+		Balances map[string]models.WallexBalanceResponse `json:"balances"`
+	}
+	if err := wallexResult("balances", body, status, err, &res, errs.ErrAssetNotFound); err != nil {
+		w.logger.Error("get balances failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return nil, err
+	}
+	return res.Balances, nil
+}
+
+func (w *WallexExchange) GetMarkets(ctx context.Context) ([]models.Market, error) {
+	endpoint := fmt.Sprintf("%s/v1/markets", w.baseURL)
+	body, status, err := w.client.Get(ctx, endpoint, w.headers())
+
+	var res struct {
+		Symbols map[string]models.WallexMarketResponse `json:"symbols"`
+	}
+	if err := wallexResult("markets", body, status, err, &res, errs.ErrExchangeUnavailable); err != nil {
+		w.logger.Error("get markets failed", zap.Int("status", status), zap.Error(err), zap.ByteString("body", body))
+		return nil, err
+	}
+
+	markets := make([]models.Market, 0, len(res.Symbols))
+	for _, m := range res.Symbols {
+		symbol := models.Symbol{Base: strings.ToUpper(m.BaseAsset), Quote: strings.ToUpper(m.QuoteAsset)}
+		w.symbols.store(m.Symbol, symbol)
+		markets = append(markets, models.Market{
+			Symbol:      symbol,
+			Tradable:    true,
+			TickSize:    models.PrecisionStep(m.TickSize),
+			StepSize:    models.PrecisionStep(m.StepSize),
+			MinQuantity: m.MinQty,
+			MaxQuantity: m.MaxQty,
+			MinNotional: m.MinNotional,
+		})
+	}
+	return markets, nil
+}
+
+func (w *WallexExchange) headers() map[string]string {
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+	if w.apiKey != "" {
+		headers["x-api-key"] = w.apiKey
+	}
+	return headers
+}
+
+// convertOrder maps an order. Market orders carry no price.
+func (w *WallexExchange) convertOrder(o models.WallexOrderResponse) (models.Order, error) {
+	var p decimals
+	filled := p.parse(o.ExecutedQty)
+	createdAt := parseWallexTime(o.TransactTime)
+	updatedAt := parseWallexTime(o.UpdatedAt)
+	if updatedAt.IsZero() {
+		updatedAt = createdAt
+	}
+	return models.Order{
+		OrderID:        o.ClientOrderID,
+		Symbol:         w.symbols.lookup(o.Symbol),
+		Side:           strings.ToLower(o.Side),
+		Type:           strings.ToLower(o.Type),
+		Status:         wallexOrderStatus(o.Status, filled),
+		Price:          p.optional(o.Price),
+		Quantity:       p.parse(o.OrigQty),
+		FilledQuantity: filled,
+		AvgFillPrice:   avgPrice(p.parse(o.ExecutedSum), filled),
+		CreatedAt:      createdAt,
+		UpdatedAt:      updatedAt,
+	}, p.err
+}
+
+func wallexSymbol(symbol models.Symbol) string {
+	return symbol.Join("")
+}
+
+func wallexEntries(entries []models.WallexOrderBookEntry) []models.OrderBookEntry {
+	result := make([]models.OrderBookEntry, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, models.OrderBookEntry{Price: e.Price, Quantity: e.Quantity})
+	}
+	return result
+}
+
+func wallexOrderStatus(status string, filled decimal.Decimal) models.OrderStatus {
+	switch strings.ToUpper(status) {
+	case "FILLED":
+		return models.OrderStatusFilled
+	case "CANCELED", "CANCELLED", "EXPIRED":
+		return models.OrderStatusCanceled
+	case "REJECTED":
+		return models.OrderStatusRejected
+	}
+	if filled.IsPositive() {
+		return models.OrderStatusPartiallyFilled
+	}
+	return models.OrderStatusNew
+}
+
+func parseWallexTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// wallexResult unwraps the {"success", "message", "result"} envelope that
+// every Wallex endpoint responds with, on errors too. A nil result only
+// checks for success.
+func wallexResult(op string, body []byte, status int, err error, result any, notFound *errs.Kind) error {
+	var envelope struct {
+		Success bool            `json:"success"`
+		Message string          `json:"message"`
+		Result  json.RawMessage `json:"result"`
+	}
+	if jsonErr := json.Unmarshal(body, &envelope); jsonErr != nil {
+		if err != nil || status < 200 || status >= 300 {
+			return httpError(op, status, body, err, notFound)
+		}
+		return errs.Wrap(errs.ErrInternal, jsonErr)
+	}
+	// The client reports non-2xx statuses as errors too; the envelope says
+	// more about them.
+	if !envelope.Success || status < 200 || status >= 300 {
+		return errs.New(wallexErrorKind(status, envelope.Message, notFound), "wallex %s: %s", op, envelope.Message)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Result, result); err != nil {
+		return errs.Wrap(errs.ErrInternal, err)
+	}
+	return nil
 }
 
-func (w *WallexExchange) GetOrderBook(ctx context.Context, symbol string) (models.OrderBook, error) {
-	w.logger.Info("Wallex: getting order book", zap.String("symbol", symbol))
-	return models.OrderBook{}, nil
+// wallexErrorKind classifies a failed call. Wallex answers most rejections
+// with 400 or 422 and a message in English or Persian, so the status only
+// settles auth, rate limit and outage failures.
+func wallexErrorKind(status int, message string, notFound *errs.Kind) *errs.Kind {
+	msg := strings.ToLower(message)
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden,
+		strings.Contains(msg, "unauthenticated"), strings.Contains(msg, "api key"):
+		return errs.ErrAuthFailed
+	case status == http.StatusTooManyRequests, strings.Contains(msg, "too many"):
+		return errs.ErrRateLimited
+	case status >= 500:
+		return errs.ErrExchangeUnavailable
+	case strings.Contains(msg, "insufficient"), strings.Contains(msg, "موجودی"):
+		return errs.ErrInsufficientFunds
+	case status == http.StatusNotFound, strings.Contains(msg, "not found"), strings.Contains(msg, "یافت نشد"):
+		return notFound
+	}
+	return errs.ErrInvalidRequest
 }
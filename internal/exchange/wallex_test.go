@@ -0,0 +1,256 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"eyeOne/config"
+	"eyeOne/internal/errs"
+	"eyeOne/internal/httpclient"
+	"eyeOne/models"
+)
+
+func newWallexTestExchange(t *testing.T, handler http.HandlerFunc) *WallexExchange {
+	return newTestAdapter(t, handler, NewWallexExchange, func(baseURL string) *config.Config {
+		return &config.Config{WallexEnv: models.EnvironmentSandbox, WallexBaseURL: baseURL, WallexAPIKey: "key"}
+	})
+}
+
+func TestNewWallexExchangeRequiresBaseURLOutsideProduction(t *testing.T) {
+	logger := zap.NewNop()
+	_, err := NewWallexExchange(httpclient.New(logger), logger, &config.Config{WallexEnv: models.EnvironmentSandbox})
+	if err == nil {
+		t.Fatal("expected an error for a sandbox without WALLEX_BASE_URL")
+	}
+}
+
+func TestWallexGetOrderBook(t *testing.T) {
+	w := newWallexTestExchange(t, func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/depth" || r.URL.Query().Get("symbol") != "BTCUSDT" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		rw.Write([]byte(`{"success":true,"message":"ok","result":{
+			"ask":[{"price":"65010.5","quantity":"0.2"},{"price":"65020","quantity":"1"}],
+			"bid":[{"price":"65000","quantity":"0.5"}]}}`))
+	})
+
+	book, err := w.GetOrderBook(context.Background(), btcUSDT, 0)
+	if err != nil {
+		t.Fatalf("GetOrderBook: %v", err)
+	}
+	if len(book.Asks) != 2 || len(book.Bids) != 1 {
+		t.Fatalf("got %d asks and %d bids, want 2 and 1", len(book.Asks), len(book.Bids))
+	}
+	assertDecimal(t, "best ask", book.Asks[0].Price, "65010.5")
+	assertDecimal(t, "best bid size", book.Bids[0].Quantity, "0.5")
+}
+
+func TestWallexBalances(t *testing.T) {
+	w := newWallexTestExchange(t, func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/account/balances" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "key" {
+			t.Errorf("missing API key header")
+		}
+		rw.Write([]byte(`{"success":true,"message":"ok","result":{"balances":{
+			"USDT":{"asset":"USDT","value":"150.5","locked":"50"},
+			"BTC":{"asset":"BTC","value":"0","locked":"0"}}}}`))
+	})
+
+	free, err := w.GetBalance(context.Background(), "usdt")
+	if err != nil {
+		t.Fatalf("GetBalance: %v", err)
+	}
+	assertDecimal(t, "free USDT", free, "100.5")
+
+	if _, err := w.GetBalance(context.Background(), "ETH"); !errors.Is(err, errs.ErrAssetNotFound) {
+		t.Errorf("GetBalance(ETH) error = %v, want %v", err, errs.ErrAssetNotFound)
+	}
+
+	balances, err := w.GetBalances(context.Background())
+	if err != nil {
+		t.Fatalf("GetBalances: %v", err)
+	}
+	if len(balances) != 1 || balances[0].Asset != "USDT" {
+		t.Fatalf("got %+v, want only the non-empty USDT balance", balances)
+	}
+	assertDecimal(t, "locked USDT", balances[0].Locked, "50")
+}
+
+func TestWallexCreateOrder(t *testing.T) {
+	tests := []struct {
+		name      string
+		orderType string
+		wantPrice bool
+	}{
+		{name: "limit", orderType: "limit", wantPrice: true},
+		{name: "market", orderType: "market", wantPrice: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newWallexTestExchange(t, func(rw http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/v1/account/orders" {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				var payload map[string]string
+				if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+					t.Fatalf("decode payload: %v", err)
+				}
+				if payload["symbol"] != "BTCUSDT" || payload["side"] != "BUY" || payload["quantity"] != "0.01" {
+					t.Errorf("unexpected payload %v", payload)
+				}
+				if _, ok := payload["price"]; ok != tt.wantPrice {
+					t.Errorf("price sent = %v, want %v", ok, tt.wantPrice)
+				}
+				rw.Write([]byte(`{"success":true,"message":"ok","result":{"clientOrderId":"abc123","status":"NEW"}}`))
+			})
+
+			id, err := w.CreateOrder(context.Background(), btcUSDT, "buy", tt.orderType, decimal.RequireFromString("0.01"), decimal.RequireFromString("65000"))
+			if err != nil {
+				t.Fatalf("CreateOrder: %v", err)
+			}
+			if id != "abc123" {
+				t.Errorf("order ID = %q, want abc123", id)
+			}
+		})
+	}
+}
+
+func TestWallexCancelOrder(t *testing.T) {
+	w := newWallexTestExchange(t, func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete || r.URL.Path != "/v1/account/orders" || r.URL.Query().Get("clientOrderId") != "abc123" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		rw.Write([]byte(`{"success":true,"message":"ok","result":{"clientOrderId":"abc123","status":"CANCELED"}}`))
+	})
+
+	if err := w.CancelOrder(context.Background(), btcUSDT, "abc123"); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+}
+
+func TestWallexErrorEnvelopes(t *testing.T) {
+	balances := func(w *WallexExchange) error {
+		_, err := w.GetBalances(context.Background())
+		return err
+	}
+	create := func(w *WallexExchange) error {
+		_, err := w.CreateOrder(context.Background(), btcUSDT, "buy", "limit", decimal.RequireFromString("1"), decimal.RequireFromString("65000"))
+		return err
+	}
+	cancel := func(w *WallexExchange) error {
+		return w.CancelOrder(context.Background(), btcUSDT, "abc123")
+	}
+
+	tests := []struct {
+		name   string
+		call   func(*WallexExchange) error
+		status int
+		body   string
+		want   *errs.Kind
+	}{
+		{
+			name:   "unreadable body",
+			call:   balances,
+			status: http.StatusOK,
+			body:   `<html>maintenance</html>`,
+			want:   errs.ErrInternal,
+		},
+		{
+			name:   "malformed number",
+			call:   balances,
+			status: http.StatusOK,
+			body:   `{"success":true,"message":"ok","result":{"balances":{"USDT":{"asset":"USDT","value":"1,5","locked":"0"}}}}`,
+			want:   errs.ErrInternal,
+		},
+		{
+			name:   "rejected key",
+			call:   balances,
+			status: http.StatusUnauthorized,
+			body:   `{"success":false,"message":"Unauthenticated.","result":{}}`,
+			want:   errs.ErrAuthFailed,
+		},
+		{
+			name:   "rejected key with success status",
+			call:   balances,
+			status: http.StatusOK,
+			body:   `{"success":false,"message":"Unauthenticated.","result":{}}`,
+			want:   errs.ErrAuthFailed,
+		},
+		{
+			name:   "rate limited",
+			call:   balances,
+			status: http.StatusTooManyRequests,
+			body:   `{"success":false,"message":"Too Many Attempts.","result":{}}`,
+			want:   errs.ErrRateLimited,
+		},
+		{
+			name:   "insufficient funds",
+			call:   create,
+			status: http.StatusBadRequest,
+			body:   `{"success":false,"message":"Insufficient balance","result":{}}`,
+			want:   errs.ErrInsufficientFunds,
+		},
+		{
+			name:   "insufficient funds in persian",
+			call:   create,
+			status: http.StatusUnprocessableEntity,
+			body:   `{"success":false,"message":"موجودی کافی نیست","result":{}}`,
+			want:   errs.ErrInsufficientFunds,
+		},
+		{
+			name:   "invalid order",
+			call:   create,
+			status: http.StatusUnprocessableEntity,
+			body:   `{"success":false,"message":"The given data was invalid.","result":{"price":["The price must be a number."]}}`,
+			want:   errs.ErrInvalidRequest,
+		},
+		{
+			name:   "unknown order",
+			call:   cancel,
+			status: http.StatusNotFound,
+			body:   `{"success":false,"message":"Not Found","result":{}}`,
+			want:   errs.ErrOrderNotFound,
+		},
+		{
+			name:   "unknown order with success status",
+			call:   cancel,
+			status: http.StatusOK,
+			body:   `{"success":false,"message":"Order not found","result":{}}`,
+			want:   errs.ErrOrderNotFound,
+		},
+		{
+			name:   "server error",
+			call:   balances,
+			status: http.StatusBadGateway,
+			body:   `{"success":false,"message":"Bad Gateway","result":{}}`,
+			want:   errs.ErrExchangeUnavailable,
+		},
+		{
+			name:   "outage page",
+			call:   cancel,
+			status: http.StatusServiceUnavailable,
+			body:   `<html>maintenance</html>`,
+			want:   errs.ErrExchangeUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newWallexTestExchange(t, func(rw http.ResponseWriter, r *http.Request) {
+				rw.WriteHeader(tt.status)
+				rw.Write([]byte(tt.body))
+			})
+
+			if err := tt.call(w); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v (%s), want %s", err, errs.Code(err), tt.want.Code())
+			}
+		})
+	}
+}
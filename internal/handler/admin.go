@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"eyeOne/internal/exchange"
+	"eyeOne/models"
+)
+
+func (h *Handler) ListExchangeStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		StatusCode: http.StatusOK,
+		Data:       h.service.Exchanges(),
+		Message:    "exchanges retrieved successfully",
+		Timestamp:  time.Now().Unix(),
+	})
+}
+
+func (h *Handler) SetExchangeEnabled(c *gin.Context) {
+	var req models.SetExchangeEnabledRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Invalid request payload",
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	exType := exchange.ExchangeType(strings.ToLower(c.Param("exchange")))
+	if err := h.service.SetExchangeEnabled(exType, *req.Enabled); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		StatusCode: http.StatusOK,
+		Data: models.ExchangeStatus{
+			Exchange:    string(exType),
+			Enabled:     *req.Enabled,
+			Environment: h.service.Environment(exType),
+		},
+		Message:   "exchange updated successfully",
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+func (h *Handler) ResetExchange(c *gin.Context) {
+	exType := exchange.ExchangeType(strings.ToLower(c.Param("exchange")))
+	if err := h.service.ResetExchange(exType); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		StatusCode: http.StatusOK,
+		Message:    "exchange state reset successfully",
+		Timestamp:  time.Now().Unix(),
+	})
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"eyeOne/internal/errs"
+	"eyeOne/models"
+)
+
+var errorStatus = map[*errs.Kind]int{
+	errs.ErrInvalidRequest:      http.StatusBadRequest,
+	errs.ErrInsufficientFunds:   http.StatusUnprocessableEntity,
+	errs.ErrSymbolNotFound:      http.StatusNotFound,
+	errs.ErrAssetNotFound:       http.StatusNotFound,
+	errs.ErrOrderNotFound:       http.StatusNotFound,
+	errs.ErrRateLimited:         http.StatusTooManyRequests,
+	errs.ErrAuthFailed:          http.StatusUnauthorized,
+	errs.ErrCredentialsRequired: http.StatusForbidden,
+	errs.ErrExchangeUnavailable: http.StatusServiceUnavailable,
+	errs.ErrExchangeNotFound:    http.StatusNotFound,
+	errs.ErrNotSupported:        http.StatusNotImplemented,
+	errs.ErrInternal:            http.StatusInternalServerError,
+}
+
+func statusFromError(err error) int {
+	if status, ok := errorStatus[errs.KindOf(err)]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+func respondError(c *gin.Context, err error) {
+	status := statusFromError(err)
+	c.JSON(status, models.ErrorResponse{
+		StatusCode: status,
+		ErrorCode:  errs.Code(err),
+		Message:    err.Error(),
+		Timestamp:  time.Now().Unix(),
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
 
 	"eyeOne/internal/exchange"
 	"eyeOne/internal/service"
@@ -47,7 +48,25 @@ func (h *Handler) CreateOrder(c *gin.Context) {
 		return
 	}
 
-	exName, exNameStr, ok := getExchange(c)
+	if req.Symbol.IsZero() {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    "symbol is required",
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	if err := models.ValidateOrderAmounts(req.Quantity, req.Price); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    err.Error(),
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	exName, _, ok := getExchange(c)
 	if !ok {
 		c.JSON(http.StatusBadRequest, models.ErrorPayload{
 			StatusCode: http.StatusBadRequest,
@@ -60,37 +79,25 @@ func (h *Handler) CreateOrder(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	orderID, err, status := h.service.CreateOrder(
+	order, err := h.service.CreateOrder(
 		ctx,
 		exName,
-		strings.ToUpper(req.Symbol),
+		req.Symbol,
 		strings.ToLower(req.Side),
 		req.OrderType,
 		req.Quantity,
 		req.Price,
 	)
 	if err != nil {
-		c.JSON(status, models.ErrorResponse{
-			StatusCode: status,
-			Message:    err.Error(),
-			Timestamp:  time.Now().Unix(),
-		})
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, models.SuccessResponse{
 		StatusCode: http.StatusCreated,
-		Data: models.OrderDataResponse{
-			OrderID:  orderID,
-			Exchange: exNameStr,
-			Symbol:   strings.ToUpper(req.Symbol),
-			Side:     strings.ToLower(req.Side),
-			Type:     req.OrderType,
-			Quantity: req.Quantity,
-			Price:    req.Price,
-		},
-		Message:   "order created successfully",
-		Timestamp: time.Now().Unix(),
+		Data:       order,
+		Message:    "order created successfully",
+		Timestamp:  time.Now().Unix(),
 	})
 }
 
@@ -105,9 +112,54 @@ func (h *Handler) CancelOrder(c *gin.Context) {
 		return
 	}
 
-	symbol := c.Param("symbol")
 	orderID := c.Param("orderID")
+	if orderID == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Missing orderID parameter",
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	symbol, err := optionalSymbol(c.Query("symbol"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    err.Error(),
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	err = h.service.CancelOrder(ctx, exName, symbol, orderID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		StatusCode: http.StatusOK,
+		Message:    "order canceled",
+		Timestamp:  time.Now().Unix(),
+	})
+}
+
+func (h *Handler) GetOrder(c *gin.Context) {
+	exName, _, ok := getExchange(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Missing or invalid exchange name",
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
 
+	orderID := c.Param("orderID")
 	if orderID == "" {
 		c.JSON(http.StatusBadRequest, models.ErrorPayload{
 			StatusCode: http.StatusBadRequest,
@@ -117,26 +169,318 @@ func (h *Handler) CancelOrder(c *gin.Context) {
 		return
 	}
 
+	symbol, err := optionalSymbol(c.Query("symbol"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    err.Error(),
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	err, status := h.service.CancelOrder(ctx, exName, symbol, orderID)
+	order, err := h.service.GetOrder(ctx, exName, symbol, orderID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		StatusCode: http.StatusOK,
+		Data:       order,
+		Message:    "order retrieved successfully",
+		Timestamp:  time.Now().Unix(),
+	})
+}
+
+func (h *Handler) ListOpenOrders(c *gin.Context) {
+	exName, _, ok := getExchange(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Missing or invalid exchange name",
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	symbol, err := optionalSymbol(c.Query("symbol"))
 	if err != nil {
-		c.JSON(status, models.ErrorResponse{
-			StatusCode: status,
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
 			Message:    err.Error(),
 			Timestamp:  time.Now().Unix(),
 		})
 		return
 	}
 
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	orders, err := h.service.ListOpenOrders(ctx, exName, symbol)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		StatusCode: http.StatusOK,
-		Message:    "order canceled",
+		Data:       models.OrderListResponse{Orders: orders},
+		Message:    "open orders retrieved successfully",
+		Timestamp:  time.Now().Unix(),
+	})
+}
+
+func (h *Handler) ListOrderHistory(c *gin.Context) {
+	exName, _, ok := getExchange(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Missing or invalid exchange name",
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	var req models.ListOrdersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Invalid query parameters",
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	symbol, err := optionalSymbol(req.Symbol)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    err.Error(),
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	query := models.OrderHistoryQuery{
+		Symbol: symbol,
+		Page:   req.Page,
+		Limit:  req.Limit,
+	}.WithDefaults()
+	if req.From > 0 {
+		query.StartTime = time.UnixMilli(req.From)
+	}
+	if req.To > 0 {
+		query.EndTime = time.UnixMilli(req.To)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	orders, err := h.service.ListOrderHistory(ctx, exName, query)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		StatusCode: http.StatusOK,
+		Data: models.OrderListResponse{
+			Orders: orders,
+			Page:   query.Page,
+			Limit:  query.Limit,
+		},
+		Message:   "order history retrieved successfully",
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+func (h *Handler) CancelAllOrders(c *gin.Context) {
+	exName, _, ok := getExchange(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Missing or invalid exchange name",
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	var req models.CancelAllOrdersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Invalid query parameters",
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	symbol, err := optionalSymbol(req.Symbol)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    err.Error(),
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 20*time.Second)
+	defer cancel()
+
+	results, err := h.service.CancelAllOrders(ctx, exName, symbol, strings.ToLower(req.Side))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		StatusCode: http.StatusOK,
+		Data:       summarizeCancelResults(results),
+		Message:    "cancel-all completed",
+		Timestamp:  time.Now().Unix(),
+	})
+}
+
+func (h *Handler) CancelAllOrdersEverywhere(c *gin.Context) {
+	var req models.CancelAllOrdersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Invalid query parameters",
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	symbol, err := optionalSymbol(req.Symbol)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    err.Error(),
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	env, err := optionalEnvironment(req.Environment)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    err.Error(),
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 20*time.Second)
+	defer cancel()
+
+	responses, err := h.service.CancelAllOrdersEverywhere(ctx, env, symbol, strings.ToLower(req.Side))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		StatusCode: http.StatusOK,
+		Data:       responses,
+		Message:    "cancel-all completed on all exchanges",
+		Timestamp:  time.Now().Unix(),
+	})
+}
+
+func (h *Handler) GetFills(c *gin.Context) {
+	exName, _, ok := getExchange(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Missing or invalid exchange name",
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	var req models.ListFillsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Invalid query parameters",
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	symbol, err := optionalSymbol(req.Symbol)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    err.Error(),
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	var since time.Time
+	if req.Since > 0 {
+		since = time.UnixMilli(req.Since)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	fills, err := h.service.GetFills(ctx, exName, symbol, since)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if fills == nil {
+		fills = []models.Fill{}
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		StatusCode: http.StatusOK,
+		Data:       models.FillListResponse{Fills: fills},
+		Message:    "fills retrieved successfully",
 		Timestamp:  time.Now().Unix(),
 	})
 }
 
+func (h *Handler) GetMarkets(c *gin.Context) {
+	exName, _, ok := getExchange(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Missing or invalid exchange name",
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	markets, updatedAt, err := h.service.GetMarkets(ctx, exName)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		StatusCode: http.StatusOK,
+		Data: models.MarketListResponse{
+			Markets:   markets,
+			UpdatedAt: updatedAt.Unix(),
+		},
+		Message:   "markets retrieved successfully",
+		Timestamp: time.Now().Unix(),
+	})
+}
+
 func (h *Handler) GetBalance(c *gin.Context) {
 	exName, _, ok := getExchange(c)
 	if !ok {
@@ -161,13 +505,9 @@ func (h *Handler) GetBalance(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	balance, err, status := h.service.GetBalance(ctx, exName, asset)
+	balance, err := h.service.GetBalance(ctx, exName, asset)
 	if err != nil {
-		c.JSON(status, models.ErrorResponse{
-			StatusCode: status,
-			Message:    err.Error(),
-			Timestamp:  time.Now().Unix(),
-		})
+		respondError(c, err)
 		return
 	}
 
@@ -182,7 +522,7 @@ func (h *Handler) GetBalance(c *gin.Context) {
 	})
 }
 
-func (h *Handler) GetOrderBook(c *gin.Context) {
+func (h *Handler) GetBalances(c *gin.Context) {
 	exName, _, ok := getExchange(c)
 	if !ok {
 		c.JSON(http.StatusBadRequest, models.ErrorPayload{
@@ -193,8 +533,40 @@ func (h *Handler) GetOrderBook(c *gin.Context) {
 		return
 	}
 
-	symbol := c.Param("symbol")
-	if err := models.ValidateSymbol(symbol, string(exName)); err != nil {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	balances, err := h.service.GetBalances(ctx, exName)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		StatusCode: http.StatusOK,
+		Data: models.ExchangeBalancesResponse{
+			Exchange:    string(exName),
+			Environment: h.service.Environment(exName),
+			Balances:    balances,
+		},
+		Message:   "balances retrieved successfully",
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+func (h *Handler) GetAllBalances(c *gin.Context) {
+	var req models.ListBalancesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Invalid query parameters",
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	env, err := optionalEnvironment(req.Environment)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorPayload{
 			StatusCode: http.StatusBadRequest,
 			Message:    err.Error(),
@@ -203,19 +575,76 @@ func (h *Handler) GetOrderBook(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
-	orderBook, err, status := h.service.GetOrderBook(ctx, exName, symbol)
+	snapshot, err := h.service.GetAllBalances(ctx, env)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		StatusCode: http.StatusOK,
+		Data:       snapshot,
+		Message:    "balances retrieved on all exchanges",
+		Timestamp:  time.Now().Unix(),
+	})
+}
+
+func (h *Handler) GetOrderBook(c *gin.Context) {
+	exName, _, ok := getExchange(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Missing or invalid exchange name",
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	symbol, err := models.ParseSymbol(c.Param("symbol"))
 	if err != nil {
-		c.JSON(status, models.ErrorResponse{
-			StatusCode: status,
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
 			Message:    err.Error(),
 			Timestamp:  time.Now().Unix(),
 		})
 		return
 	}
 
+	var req models.GetOrderBookRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    "limit must be a positive integer",
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	step := decimal.Zero
+	if req.Step != "" {
+		step, err = decimal.NewFromString(req.Step)
+		if err != nil || !step.IsPositive() {
+			c.JSON(http.StatusBadRequest, models.ErrorPayload{
+				StatusCode: http.StatusBadRequest,
+				Message:    "step must be a positive number",
+				Timestamp:  time.Now().Unix(),
+			})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	orderBook, err := h.service.GetOrderBook(ctx, exName, symbol, req.Limit, step)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		StatusCode: http.StatusOK,
 		Data:       orderBook,
@@ -223,3 +652,236 @@ func (h *Handler) GetOrderBook(c *gin.Context) {
 		Timestamp:  time.Now().Unix(),
 	})
 }
+
+func (h *Handler) GetTicker(c *gin.Context) {
+	exName, _, ok := getExchange(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Missing or invalid exchange name",
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	symbol, err := models.ParseSymbol(c.Param("symbol"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    err.Error(),
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	ticker, err := h.service.GetTicker(ctx, exName, symbol)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		StatusCode: http.StatusOK,
+		Data:       ticker,
+		Message:    "ticker retrieved successfully",
+		Timestamp:  time.Now().Unix(),
+	})
+}
+
+func (h *Handler) GetTickers(c *gin.Context) {
+	exName, _, ok := getExchange(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Missing or invalid exchange name",
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	var req models.ListTickersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    "symbols is required",
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	var symbols []models.Symbol
+	for _, value := range strings.Split(req.Symbols, ",") {
+		symbol, err := models.ParseSymbol(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorPayload{
+				StatusCode: http.StatusBadRequest,
+				Message:    err.Error(),
+				Timestamp:  time.Now().Unix(),
+			})
+			return
+		}
+		symbols = append(symbols, symbol)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	tickers, err := h.service.GetTickers(ctx, exName, symbols)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		StatusCode: http.StatusOK,
+		Data:       models.TickerListResponse{Tickers: tickers},
+		Message:    "tickers retrieved successfully",
+		Timestamp:  time.Now().Unix(),
+	})
+}
+
+func (h *Handler) GetCandles(c *gin.Context) {
+	exName, _, ok := getExchange(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Missing or invalid exchange name",
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	symbol, err := models.ParseSymbol(c.Param("symbol"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    err.Error(),
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	var req models.ListCandlesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    "interval is required; from, to and limit must be positive",
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	interval, err := models.ParseCandleInterval(req.Interval)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    err.Error(),
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	var from, to time.Time
+	if req.From > 0 {
+		from = time.UnixMilli(req.From)
+	}
+	if req.To > 0 {
+		to = time.UnixMilli(req.To)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	candles, err := h.service.GetCandles(ctx, exName, symbol, interval, from, to, req.Limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		StatusCode: http.StatusOK,
+		Data:       models.CandleListResponse{Interval: interval, Candles: candles},
+		Message:    "candles retrieved successfully",
+		Timestamp:  time.Now().Unix(),
+	})
+}
+
+func (h *Handler) GetRecentTrades(c *gin.Context) {
+	exName, _, ok := getExchange(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Missing or invalid exchange name",
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	symbol, err := models.ParseSymbol(c.Param("symbol"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    err.Error(),
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	var req models.ListTradesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    "limit must be a positive integer",
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	trades, err := h.service.GetRecentTrades(ctx, exName, symbol, req.Limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		StatusCode: http.StatusOK,
+		Data:       models.TradeListResponse{Exchange: string(exName), Symbol: symbol, Trades: trades},
+		Message:    "recent trades retrieved successfully",
+		Timestamp:  time.Now().Unix(),
+	})
+}
+
+func optionalSymbol(value string) (models.Symbol, error) {
+	if value == "" {
+		return models.Symbol{}, nil
+	}
+	return models.ParseSymbol(value)
+}
+
+func optionalEnvironment(value string) (models.Environment, error) {
+	if value == "" {
+		return "", nil
+	}
+	return models.ParseEnvironment(value)
+}
+
+func summarizeCancelResults(results []models.CancelResult) models.CancelAllResponse {
+	summary := models.CancelAllResponse{Results: results}
+	if summary.Results == nil {
+		summary.Results = []models.CancelResult{}
+	}
+	for _, r := range results {
+		if r.Canceled {
+			summary.Canceled++
+		} else {
+			summary.Failed++
+		}
+	}
+	return summary
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"eyeOne/internal/stream"
+	"eyeOne/models"
+	"eyeOne/pkg/logger"
+)
+
+const (
+	defaultStreamDepth = 50
+	streamWriteTimeout = 10 * time.Second
+	streamPingInterval = 30 * time.Second
+	streamPongTimeout  = time.Minute
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 16 * 1024,
+}
+
+// StreamOrderBook upgrades to a WebSocket and pushes the locally maintained
+// order book whenever it changes. Clients that read slowly skip to the
+// latest book. Nothing is read from the client except control frames.
+func (h *Handler) StreamOrderBook(c *gin.Context) {
+	exName, _, ok := getExchange(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Missing or invalid exchange name",
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	symbol, err := models.ParseSymbol(c.Param("symbol"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    err.Error(),
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+
+	var req models.StreamOrderBookRequest
+	if err := c.ShouldBindQuery(&req); err != nil || req.Limit > stream.MaxDepth {
+		c.JSON(http.StatusBadRequest, models.ErrorPayload{
+			StatusCode: http.StatusBadRequest,
+			Message:    "limit must be between 1 and 1000",
+			Timestamp:  time.Now().Unix(),
+		})
+		return
+	}
+	depth := req.Limit
+	if depth == 0 {
+		depth = defaultStreamDepth
+	}
+
+	sub, err := h.service.SubscribeOrderBook(exName, symbol)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer sub.Close()
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade has already replied to the client.
+		return
+	}
+	defer conn.Close()
+
+	log := logger.GetLogger().With(zap.String("exchange", string(exName)), zap.String("symbol", symbol.String()))
+	log.Info("Order book stream client connected", zap.String("clientIP", c.ClientIP()))
+	defer log.Info("Order book stream client disconnected")
+
+	// The read side only exists to process pongs and notice the client
+	// going away.
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		conn.SetReadLimit(512)
+		conn.SetReadDeadline(time.Now().Add(streamPongTimeout))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(streamPongTimeout))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(streamPingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-gone:
+			return
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout)); err != nil {
+				return
+			}
+		case book, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			book.Bids = book.Bids[:min(depth, len(book.Bids))]
+			book.Asks = book.Asks[:min(depth, len(book.Asks))]
+
+			conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			err := conn.WriteJSON(models.OrderBookStreamMessage{
+				Exchange:  string(exName),
+				Symbol:    symbol,
+				OrderBook: book,
+			})
+			if err != nil {
+				log.Warn("Failed to write order book to stream client", zap.Error(err))
+				return
+			}
+		}
+	}
+}
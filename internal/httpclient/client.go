@@ -40,7 +40,7 @@ func (c *Client) Get(ctx context.Context, url string, headers map[string]string)
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		c.logger.Error("GET request failed", zap.Error(err))
-		return nil, resp.StatusCode, err
+		return nil, 0, err
 	}
 	defer resp.Body.Close()
 
@@ -74,7 +74,7 @@ func (c *Client) PostJSON(ctx context.Context, url string, body interface{}, hea
 
 	if err != nil {
 		c.logger.Error("POST request failed", zap.Error(err))
-		return nil, resp.StatusCode, err
+		return nil, 0, err
 	}
 	defer resp.Body.Close()
 
@@ -85,7 +85,7 @@ func (c *Client) PostJSON(ctx context.Context, url string, body interface{}, hea
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, resp.StatusCode, errors.New(string(respBody))
+		return respBody, resp.StatusCode, errors.New(string(respBody))
 	}
 	return respBody, resp.StatusCode, nil
 }
@@ -120,3 +120,40 @@ func (c *Client) Delete(ctx context.Context, url string, headers map[string]stri
 
 	return respBody, resp.StatusCode, nil
 }
+
+// Do sends a request with a raw body and, when signer is not nil, signs it
+// after all other headers are set.
+func (c *Client) Do(ctx context.Context, method, url string, body []byte, headers map[string]string, signer Signer) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		c.logger.Error("failed to create request", zap.String("method", method), zap.Error(err))
+		return nil, 0, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if signer != nil {
+		if err := signer.Sign(req, body); err != nil {
+			c.logger.Error("failed to sign request", zap.String("method", method), zap.Error(err))
+			return nil, 0, err
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		c.logger.Error("request failed", zap.String("method", method), zap.Error(err))
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.logger.Error("failed to read response body", zap.String("method", method), zap.Error(err))
+		return nil, resp.StatusCode, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return respBody, resp.StatusCode, errors.New(string(respBody))
+	}
+	return respBody, resp.StatusCode, nil
+}
@@ -0,0 +1,58 @@
+package httpclient
+
+import (
+	"crypto/hmac"
+	"hash"
+	"net/http"
+	"time"
+)
+
+// Signer authenticates a request right before it is sent. body is the exact
+// payload that will go on the wire.
+type Signer interface {
+	Sign(req *http.Request, body []byte) error
+}
+
+// SignerFunc adapts a function to the Signer interface.
+type SignerFunc func(req *http.Request, body []byte) error
+
+func (f SignerFunc) Sign(req *http.Request, body []byte) error {
+	return f(req, body)
+}
+
+// HMACSigner implements the common "HMAC over timestamp and request" scheme.
+// Venues differ only in what they hash and where the result goes, which is
+// what the function fields describe.
+type HMACSigner struct {
+	Secret []byte
+	Hash   func() hash.Hash
+	// Encode turns the raw MAC into its header form, e.g. hex or base64.
+	Encode func([]byte) string
+	// Timestamp formats the signing time the way the venue expects.
+	Timestamp func(time.Time) string
+	// Message builds the string to sign.
+	Message func(timestamp string, req *http.Request, body []byte) string
+	// Apply sets the authentication headers.
+	Apply func(header http.Header, timestamp, signature string)
+
+	Now func() time.Time
+}
+
+func (s *HMACSigner) Sign(req *http.Request, body []byte) error {
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	timestamp := s.Timestamp(now())
+
+	mac := hmac.New(s.Hash, s.Secret)
+	mac.Write([]byte(s.Message(timestamp, req, body)))
+	s.Apply(req.Header, timestamp, s.Encode(mac.Sum(nil)))
+	return nil
+}
+
+// RequestTarget returns the path and query of req as it appears on the
+// request line, which is what most venues include in the signature.
+func RequestTarget(req *http.Request) string {
+	return req.URL.RequestURI()
+}
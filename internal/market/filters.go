@@ -0,0 +1,86 @@
+package market
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"eyeOne/internal/errs"
+	"eyeOne/models"
+)
+
+type FilterMode string
+
+const (
+	// FilterRound snaps price and quantity onto the venue grid before sending.
+	FilterRound FilterMode = "round"
+	// FilterReject refuses orders that are not already on the venue grid.
+	FilterReject FilterMode = "reject"
+)
+
+func ParseFilterMode(value string) (FilterMode, error) {
+	switch mode := FilterMode(strings.ToLower(value)); mode {
+	case FilterRound, FilterReject:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown order filter mode %q (expected %q or %q)", value, FilterRound, FilterReject)
+	}
+}
+
+// ApplyFilters checks an order against the market's tick size, lot step,
+// quantity bounds and minimum notional. In round mode the returned price and
+// quantity are aligned to the grid: quantity is always rounded down and price
+// is rounded away from the spread (down for buys, up for sells) so rounding
+// never makes an order more aggressive than requested.
+func ApplyFilters(m models.Market, side, orderType string, quantity, price decimal.Decimal, mode FilterMode) (decimal.Decimal, decimal.Decimal, error) {
+	if !m.Tradable {
+		return quantity, price, errs.New(errs.ErrInvalidRequest, "market %s is not open for trading", m.Symbol)
+	}
+
+	isMarket := strings.EqualFold(orderType, "market")
+	if !isMarket && m.TickSize.IsPositive() {
+		aligned := floorToStep(price, m.TickSize)
+		if strings.EqualFold(side, "sell") {
+			aligned = ceilToStep(price, m.TickSize)
+		}
+		if !aligned.Equal(price) {
+			if mode == FilterReject {
+				return quantity, price, errs.New(errs.ErrInvalidRequest, "price %s is not a multiple of tick size %s", price, m.TickSize)
+			}
+			price = aligned
+		}
+	}
+
+	if m.StepSize.IsPositive() {
+		aligned := floorToStep(quantity, m.StepSize)
+		if !aligned.Equal(quantity) {
+			if mode == FilterReject {
+				return quantity, price, errs.New(errs.ErrInvalidRequest, "quantity %s is not a multiple of lot step %s", quantity, m.StepSize)
+			}
+			quantity = aligned
+		}
+	}
+
+	if !quantity.IsPositive() || quantity.LessThan(m.MinQuantity) {
+		return quantity, price, errs.New(errs.ErrInvalidRequest, "quantity %s is below the minimum %s for %s", quantity, m.MinQuantity, m.Symbol)
+	}
+	if m.MaxQuantity.IsPositive() && quantity.GreaterThan(m.MaxQuantity) {
+		return quantity, price, errs.New(errs.ErrInvalidRequest, "quantity %s exceeds the maximum %s for %s", quantity, m.MaxQuantity, m.Symbol)
+	}
+	if m.MinNotional.IsPositive() && price.IsPositive() {
+		if notional := quantity.Mul(price); notional.LessThan(m.MinNotional) {
+			return quantity, price, errs.New(errs.ErrInvalidRequest, "order value %s is below the minimum notional %s for %s", notional, m.MinNotional, m.Symbol)
+		}
+	}
+
+	return quantity, price, nil
+}
+
+func floorToStep(value, step decimal.Decimal) decimal.Decimal {
+	return value.Div(step).Floor().Mul(step)
+}
+
+func ceilToStep(value, step decimal.Decimal) decimal.Decimal {
+	return value.Div(step).Ceil().Mul(step)
+}
@@ -0,0 +1,143 @@
+package market
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"eyeOne/internal/errs"
+	"eyeOne/internal/exchange"
+	"eyeOne/models"
+	"eyeOne/pkg/logger"
+)
+
+// Registry caches the trading rules of every market on every registered
+// exchange and keeps them fresh in the background.
+type Registry struct {
+	exchanges map[exchange.ExchangeType]exchange.Exchange
+	mode      FilterMode
+	log       *zap.Logger
+
+	mu        sync.RWMutex
+	markets   map[exchange.ExchangeType]map[models.Symbol]models.Market
+	updatedAt map[exchange.ExchangeType]time.Time
+}
+
+func NewRegistry(exchanges map[exchange.ExchangeType]exchange.Exchange, mode FilterMode) *Registry {
+	return &Registry{
+		exchanges: exchanges,
+		mode:      mode,
+		log:       logger.GetLogger(),
+		markets:   make(map[exchange.ExchangeType]map[models.Symbol]models.Market),
+		updatedAt: make(map[exchange.ExchangeType]time.Time),
+	}
+}
+
+// Run loads every exchange's markets and refreshes them on each interval
+// until ctx is canceled.
+func (r *Registry) Run(ctx context.Context, interval time.Duration) {
+	r.Refresh(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Refresh(ctx)
+		}
+	}
+}
+
+// Refresh reloads all exchanges. A failing exchange keeps its previous data.
+func (r *Registry) Refresh(ctx context.Context) {
+	for exType := range r.exchanges {
+		if err := r.refreshExchange(ctx, exType); err != nil {
+			r.log.Warn("Failed to refresh markets",
+				zap.String("exchange", string(exType)),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+func (r *Registry) refreshExchange(ctx context.Context, exType exchange.ExchangeType) error {
+	ex, ok := r.exchanges[exType]
+	if !ok {
+		return errs.New(errs.ErrExchangeNotFound, "exchange %s not found", exType)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	list, err := ex.GetMarkets(ctx)
+	if err != nil {
+		return err
+	}
+
+	bySymbol := make(map[models.Symbol]models.Market, len(list))
+	for _, m := range list {
+		bySymbol[m.Symbol] = m
+	}
+
+	r.mu.Lock()
+	r.markets[exType] = bySymbol
+	r.updatedAt[exType] = time.Now()
+	r.mu.Unlock()
+
+	r.log.Info("Markets refreshed",
+		zap.String("exchange", string(exType)),
+		zap.Int("count", len(bySymbol)),
+	)
+	return nil
+}
+
+// Markets returns the cached markets of an exchange sorted by symbol,
+// loading them first if they have never been fetched.
+func (r *Registry) Markets(ctx context.Context, exType exchange.ExchangeType) ([]models.Market, time.Time, error) {
+	r.mu.RLock()
+	bySymbol, loaded := r.markets[exType]
+	updatedAt := r.updatedAt[exType]
+	r.mu.RUnlock()
+
+	if !loaded {
+		if err := r.refreshExchange(ctx, exType); err != nil {
+			return nil, time.Time{}, err
+		}
+		return r.Markets(ctx, exType)
+	}
+
+	list := make([]models.Market, 0, len(bySymbol))
+	for _, m := range bySymbol {
+		list = append(list, m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Symbol.String() < list[j].Symbol.String() })
+	return list, updatedAt, nil
+}
+
+// ApplyFilters validates an order against the cached market rules. Orders
+// on exchanges whose markets have not been loaded yet pass through unchanged.
+func (r *Registry) ApplyFilters(exType exchange.ExchangeType, symbol models.Symbol, side, orderType string, quantity, price decimal.Decimal) (decimal.Decimal, decimal.Decimal, error) {
+	r.mu.RLock()
+	bySymbol, loaded := r.markets[exType]
+	m, found := bySymbol[symbol]
+	r.mu.RUnlock()
+
+	if !loaded {
+		r.log.Warn("Market rules not loaded, skipping order filters",
+			zap.String("exchange", string(exType)),
+			zap.String("symbol", symbol.String()),
+		)
+		return quantity, price, nil
+	}
+	if !found {
+		return quantity, price, errs.New(errs.ErrSymbolNotFound, "symbol %s is not listed on %s", symbol, exType)
+	}
+	return ApplyFilters(m, side, orderType, quantity, price, r.mode)
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"eyeOne/models"
+	"eyeOne/pkg/logger"
+)
+
+const adminTokenHeader = "X-Admin-Token"
+
+// AdminMiddleware guards operator endpoints with a shared token. Without a
+// configured token the admin endpoints are switched off.
+func AdminMiddleware(token string) gin.HandlerFunc {
+	log := logger.GetLogger()
+
+	return func(c *gin.Context) {
+		if token == "" {
+			c.JSON(http.StatusForbidden, models.ErrorPayload{
+				Message:    "Admin endpoints are disabled. Set ADMIN_TOKEN to enable them.",
+				Timestamp:  time.Now().Unix(),
+				StatusCode: http.StatusForbidden,
+			})
+			c.Abort()
+			return
+		}
+
+		provided := c.GetHeader(adminTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			log.Warn("Rejected admin request",
+				zap.String("path", c.Request.URL.Path),
+				zap.String("clientIP", c.ClientIP()),
+			)
+
+			c.JSON(http.StatusUnauthorized, models.ErrorPayload{
+				Message:    "Invalid or missing " + adminTokenHeader + " header",
+				Timestamp:  time.Now().Unix(),
+				StatusCode: http.StatusUnauthorized,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
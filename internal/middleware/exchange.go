@@ -9,33 +9,31 @@ import (
 	"go.uber.org/zap"
 
 	"eyeOne/internal/exchange"
+	"eyeOne/internal/service"
 	"eyeOne/models"
 	"eyeOne/pkg/logger"
 )
 
-var validExchanges = map[string]exchange.ExchangeType{
-	"binance": exchange.Binance,
-	"kucoin":  exchange.KuCoin,
-	"bitpin":  exchange.Bitpin,
-}
-
-var allowedExchanges = []string{"bitpin"}
-
-func ExchangeMiddleware() gin.HandlerFunc {
+func ExchangeMiddleware(ts *service.TradingService) gin.HandlerFunc {
 	log := logger.GetLogger()
 
 	return func(c *gin.Context) {
+		// Named accounts are addressed as binance:mm or, on routes that
+		// have it, with a separate :account segment.
 		raw := strings.ToLower(c.Param("exchange"))
+		if account := strings.ToLower(c.Param("account")); account != "" {
+			raw = string(exchange.AccountType(exchange.ExchangeType(raw), account))
+		}
 
-		_, ok := validExchanges[raw]
-		if !ok {
+		registered, enabled := ts.ExchangeStatus(exchange.ExchangeType(raw))
+		if !registered {
 			log.Warn("Invalid exchange parameter",
 				zap.String("exchange", raw),
 				zap.String("path", c.Request.URL.Path),
 			)
 
 			c.JSON(http.StatusBadRequest, models.ErrorPayload{
-				Message:    "Invalid exchange. Allowed: bitpin",
+				Message:    "Invalid exchange. Allowed: " + joinExchanges(ts.EnabledExchanges()),
 				Timestamp:  time.Now().Unix(),
 				StatusCode: http.StatusBadRequest,
 			})
@@ -43,14 +41,14 @@ func ExchangeMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		if raw != "bitpin" {
-			log.Warn("Exchange not available yet",
+		if !enabled {
+			log.Warn("Exchange disabled",
 				zap.String("exchange", raw),
 				zap.String("path", c.Request.URL.Path),
 			)
 
 			c.JSON(http.StatusServiceUnavailable, models.ErrorPayload{
-				Message:    "Exchange '" + raw + "' is not available at the moment. Only 'bitpin' is supported.",
+				Message:    "Exchange '" + raw + "' is disabled. Enabled: " + joinExchanges(ts.EnabledExchanges()),
 				Timestamp:  time.Now().Unix(),
 				StatusCode: http.StatusServiceUnavailable,
 			})
@@ -58,12 +56,26 @@ func ExchangeMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		env := ts.Environment(exchange.ExchangeType(raw))
 		log.Info("Exchange validated",
 			zap.String("exchange", raw),
+			zap.String("environment", string(env)),
 			zap.String("path", c.Request.URL.Path),
 		)
 
+		c.Header("X-Exchange-Environment", string(env))
 		c.Set("exchange", raw)
 		c.Next()
 	}
 }
+
+func joinExchanges(exchanges []exchange.ExchangeType) string {
+	if len(exchanges) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(exchanges))
+	for _, exType := range exchanges {
+		names = append(names, string(exType))
+	}
+	return strings.Join(names, ", ")
+}
@@ -2,115 +2,821 @@ package service
 
 import (
 	"context"
-	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 
+	"eyeOne/internal/errs"
 	"eyeOne/internal/exchange"
+	"eyeOne/internal/market"
+	"eyeOne/internal/stream"
 	"eyeOne/models"
 	"eyeOne/pkg/logger"
 )
 
+const (
+	defaultFillsLookback = 24 * time.Hour
+	maxTickerBatch       = 20
+	defaultCandleLimit   = 500
+	maxCandleLimit       = 1000
+	defaultTradeLimit    = 100
+	maxTradeLimit        = 1000
+	maxBookLimit         = 5000
+)
+
 type TradingService struct {
 	exchanges map[exchange.ExchangeType]exchange.Exchange
+	markets   *market.Registry
+	streams   *stream.Manager
 	log       *zap.Logger
+
+	// publicOnly holds exchanges configured without API credentials, which
+	// only serve market data.
+	publicOnly map[exchange.ExchangeType]bool
+
+	// environments records which venue deployment each exchange talks to.
+	// Exchanges missing from it are production.
+	environments map[exchange.ExchangeType]models.Environment
+
+	mu      sync.RWMutex
+	enabled map[exchange.ExchangeType]bool
 }
 
-func NewTradingService(exchanges map[exchange.ExchangeType]exchange.Exchange) *TradingService {
-	return &TradingService{
-		exchanges: exchanges,
-		log:       logger.GetLogger(),
+func NewTradingService(exchanges map[exchange.ExchangeType]exchange.Exchange, markets *market.Registry, streams *stream.Manager, enabled, publicOnly []exchange.ExchangeType, environments map[exchange.ExchangeType]models.Environment) *TradingService {
+	ts := &TradingService{
+		exchanges:    exchanges,
+		markets:      markets,
+		streams:      streams,
+		log:          logger.GetLogger(),
+		publicOnly:   make(map[exchange.ExchangeType]bool, len(publicOnly)),
+		environments: environments,
+		enabled:      make(map[exchange.ExchangeType]bool, len(exchanges)),
+	}
+	for _, exType := range enabled {
+		if _, ok := exchanges[exType]; ok {
+			ts.enabled[exType] = true
+		}
 	}
+	for _, exType := range publicOnly {
+		ts.publicOnly[exType] = true
+	}
+	return ts
 }
 
-func (ts *TradingService) getExchange(exType exchange.ExchangeType) (exchange.Exchange, error, int) {
+func (ts *TradingService) getExchange(exType exchange.ExchangeType) (exchange.Exchange, error) {
 	ex, ok := ts.exchanges[exType]
 	if !ok {
 		ts.log.Error("Exchange not found",
 			zap.String("exchange", string(exType)),
 		)
-		return nil, fmt.Errorf("exchange %s not found", exType), 500
+		return nil, errs.New(errs.ErrExchangeNotFound, "exchange %s not found", exType)
+	}
+	if !ts.IsEnabled(exType) {
+		ts.log.Warn("Exchange disabled",
+			zap.String("exchange", string(exType)),
+		)
+		return nil, errs.New(errs.ErrExchangeUnavailable, "exchange %s is disabled", exType)
 	}
-	return ex, nil, 200
+	return ex, nil
 }
 
-func (ts *TradingService) CreateOrder(ctx context.Context, exType exchange.ExchangeType, symbol, side, orderType string, quantity, price float64) (string, error, int) {
+// getTradingExchange is getExchange for operations that need API
+// credentials, i.e. everything except market data.
+func (ts *TradingService) getTradingExchange(exType exchange.ExchangeType) (exchange.Exchange, error) {
+	ex, err := ts.getExchange(exType)
+	if err != nil {
+		return nil, err
+	}
+	if ts.publicOnly[exType] {
+		ts.log.Warn("Private operation on public-only exchange",
+			zap.String("exchange", string(exType)),
+		)
+		return nil, errs.New(errs.ErrCredentialsRequired, "exchange %s has no API credentials configured; only market data is available", exType)
+	}
+	return ex, nil
+}
+
+func (ts *TradingService) mode(exType exchange.ExchangeType) models.ExchangeMode {
+	if ts.publicOnly[exType] {
+		return models.ExchangeModePublic
+	}
+	return models.ExchangeModeTrading
+}
+
+// Environment reports the venue deployment an exchange is wired to.
+func (ts *TradingService) Environment(exType exchange.ExchangeType) models.Environment {
+	if env, ok := ts.environments[exType]; ok {
+		return env
+	}
+	return models.EnvironmentProduction
+}
+
+// ExchangeStatus reports whether an exchange is registered and whether it
+// currently accepts requests.
+func (ts *TradingService) ExchangeStatus(exType exchange.ExchangeType) (registered, enabled bool) {
+	if _, ok := ts.exchanges[exType]; !ok {
+		return false, false
+	}
+	return true, ts.IsEnabled(exType)
+}
+
+func (ts *TradingService) IsEnabled(exType exchange.ExchangeType) bool {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	return ts.enabled[exType]
+}
+
+func (ts *TradingService) SetExchangeEnabled(exType exchange.ExchangeType, enabled bool) error {
+	if _, ok := ts.exchanges[exType]; !ok {
+		return errs.New(errs.ErrExchangeNotFound, "exchange %s not found", exType)
+	}
+
+	ts.mu.Lock()
+	ts.enabled[exType] = enabled
+	ts.mu.Unlock()
+
+	ts.log.Info("Exchange availability changed",
+		zap.String("exchange", string(exType)),
+		zap.Bool("enabled", enabled),
+	)
+	return nil
+}
+
+// ResetExchange wipes the state of a simulated exchange.
+func (ts *TradingService) ResetExchange(exType exchange.ExchangeType) error {
+	ex, ok := ts.exchanges[exType]
+	if !ok {
+		return errs.New(errs.ErrExchangeNotFound, "exchange %s not found", exType)
+	}
+	resetter, ok := ex.(exchange.Resetter)
+	if !ok {
+		return errs.New(errs.ErrNotSupported, "exchange %s cannot be reset", exType)
+	}
+
+	resetter.Reset()
+	ts.log.Warn("Exchange state reset", zap.String("exchange", string(exType)))
+	return nil
+}
+
+// Exchanges lists every registered exchange sorted by name.
+func (ts *TradingService) Exchanges() []models.ExchangeStatus {
+	statuses := make([]models.ExchangeStatus, 0, len(ts.exchanges))
+	for exType, ex := range ts.exchanges {
+		status := models.ExchangeStatus{
+			Exchange:    string(exType),
+			Enabled:     ts.IsEnabled(exType),
+			Mode:        ts.mode(exType),
+			Environment: ts.Environment(exType),
+		}
+		if limited, ok := ex.(exchange.Limited); ok {
+			status.Unsupported = limited.Unsupported()
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Exchange < statuses[j].Exchange
+	})
+	return statuses
+}
+
+// EnabledExchanges returns the names of the exchanges accepting requests.
+func (ts *TradingService) EnabledExchanges() []exchange.ExchangeType {
+	var enabled []exchange.ExchangeType
+	for _, status := range ts.Exchanges() {
+		if status.Enabled {
+			enabled = append(enabled, exchange.ExchangeType(status.Exchange))
+		}
+	}
+	return enabled
+}
+
+func (ts *TradingService) CreateOrder(ctx context.Context, exType exchange.ExchangeType, symbol models.Symbol, side, orderType string, quantity, price decimal.Decimal) (models.OrderDataResponse, error) {
 	ts.log.Info("Creating order",
 		zap.String("exchange", string(exType)),
-		zap.String("symbol", symbol),
+		zap.String("environment", string(ts.Environment(exType))),
+		zap.String("symbol", symbol.String()),
 		zap.String("side", side),
 		zap.String("orderType", orderType),
-		zap.Float64("quantity", quantity),
-		zap.Float64("price", price),
+		zap.Stringer("quantity", quantity),
+		zap.Stringer("price", price),
 	)
 
-	ex, err, statusCode := ts.getExchange(exType)
+	ex, err := ts.getTradingExchange(exType)
 	if err != nil {
-		return "", err, statusCode
+		return models.OrderDataResponse{}, err
+	}
+
+	if ts.markets != nil {
+		filteredQty, filteredPrice, err := ts.markets.ApplyFilters(exType.Venue(), symbol, side, orderType, quantity, price)
+		if err != nil {
+			ts.log.Warn("Order rejected by market filters", zap.Error(err))
+			return models.OrderDataResponse{}, err
+		}
+		if !filteredQty.Equal(quantity) || !filteredPrice.Equal(price) {
+			ts.log.Info("Order adjusted to market filters",
+				zap.Stringer("quantity", filteredQty),
+				zap.Stringer("price", filteredPrice),
+			)
+		}
+		quantity, price = filteredQty, filteredPrice
 	}
 
-	orderID, err, status := ex.CreateOrder(ctx, symbol, side, orderType, quantity, price)
+	orderID, err := ex.CreateOrder(ctx, symbol, side, orderType, quantity, price)
 	if err != nil {
-		ts.log.Error("Failed to create order", zap.Error(err))
-		return "", err, status
+		ts.log.Error("Failed to create order", zap.Error(err), zap.String("errorCode", errs.Code(err)))
+		return models.OrderDataResponse{}, err
 	}
 
-	return orderID, nil, status
+	return models.OrderDataResponse{
+		OrderID:     orderID,
+		Exchange:    string(exType),
+		Environment: ts.Environment(exType),
+		Symbol:      symbol,
+		Side:        side,
+		Type:        orderType,
+		Quantity:    quantity,
+		Price:       price,
+	}, nil
 }
 
-func (ts *TradingService) CancelOrder(ctx context.Context, exType exchange.ExchangeType, symbol, orderID string) (error, int) {
+func (ts *TradingService) GetMarkets(ctx context.Context, exType exchange.ExchangeType) ([]models.Market, time.Time, error) {
+	ts.log.Info("Getting markets", zap.String("exchange", string(exType)))
+
+	if _, err := ts.getExchange(exType); err != nil {
+		return nil, time.Time{}, err
+	}
+	if ts.markets == nil {
+		return nil, time.Time{}, errs.New(errs.ErrNotSupported, "market metadata is not enabled")
+	}
+
+	markets, updatedAt, err := ts.markets.Markets(ctx, exType.Venue())
+	if err != nil {
+		ts.log.Error("Failed to get markets", zap.Error(err), zap.String("errorCode", errs.Code(err)))
+	}
+	return markets, updatedAt, err
+}
+
+func (ts *TradingService) CancelOrder(ctx context.Context, exType exchange.ExchangeType, symbol models.Symbol, orderID string) error {
 	ts.log.Info("Canceling order",
 		zap.String("exchange", string(exType)),
-		zap.String("symbol", symbol),
+		zap.String("symbol", symbol.String()),
 		zap.String("orderId", orderID),
 	)
 
-	ex, err, status := ts.getExchange(exType)
+	ex, err := ts.getTradingExchange(exType)
 	if err != nil {
-		return err, status
+		return err
 	}
 
-	err, status = ex.CancelOrder(ctx, symbol, orderID)
+	err = ex.CancelOrder(ctx, symbol, orderID)
 	if err != nil {
-		ts.log.Error("Failed to cancel order", zap.Error(err))
+		ts.log.Error("Failed to cancel order", zap.Error(err), zap.String("errorCode", errs.Code(err)))
 	}
-	return err, status
+	return err
 }
 
-func (ts *TradingService) GetBalance(ctx context.Context, exType exchange.ExchangeType, asset string) (float64, error, int) {
+func (ts *TradingService) GetOrder(ctx context.Context, exType exchange.ExchangeType, symbol models.Symbol, orderID string) (models.Order, error) {
+	ts.log.Info("Getting order",
+		zap.String("exchange", string(exType)),
+		zap.String("symbol", symbol.String()),
+		zap.String("orderId", orderID),
+	)
+
+	ex, err := ts.getTradingExchange(exType)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	order, err := ex.GetOrder(ctx, symbol, orderID)
+	if err != nil {
+		ts.log.Error("Failed to get order", zap.Error(err), zap.String("errorCode", errs.Code(err)))
+		return models.Order{}, err
+	}
+
+	order.Exchange = string(exType)
+	return order, nil
+}
+
+func (ts *TradingService) ListOpenOrders(ctx context.Context, exType exchange.ExchangeType, symbol models.Symbol) ([]models.Order, error) {
+	ts.log.Info("Listing open orders",
+		zap.String("exchange", string(exType)),
+		zap.String("symbol", symbol.String()),
+	)
+
+	ex, err := ts.getTradingExchange(exType)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := ex.ListOpenOrders(ctx, symbol)
+	if err != nil {
+		ts.log.Error("Failed to list open orders", zap.Error(err), zap.String("errorCode", errs.Code(err)))
+		return nil, err
+	}
+
+	return withExchange(orders, exType), nil
+}
+
+func (ts *TradingService) ListOrderHistory(ctx context.Context, exType exchange.ExchangeType, query models.OrderHistoryQuery) ([]models.Order, error) {
+	query = query.WithDefaults()
+
+	ts.log.Info("Listing order history",
+		zap.String("exchange", string(exType)),
+		zap.String("symbol", query.Symbol.String()),
+		zap.Time("from", query.StartTime),
+		zap.Time("to", query.EndTime),
+		zap.Int("page", query.Page),
+		zap.Int("limit", query.Limit),
+	)
+
+	if !query.StartTime.IsZero() && !query.EndTime.IsZero() && query.EndTime.Before(query.StartTime) {
+		return nil, errs.New(errs.ErrInvalidRequest, "end time must not be before start time")
+	}
+
+	ex, err := ts.getTradingExchange(exType)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := ex.ListOrderHistory(ctx, query)
+	if err != nil {
+		ts.log.Error("Failed to list order history", zap.Error(err), zap.String("errorCode", errs.Code(err)))
+		return nil, err
+	}
+
+	return withExchange(orders, exType), nil
+}
+
+func (ts *TradingService) CancelAllOrders(ctx context.Context, exType exchange.ExchangeType, symbol models.Symbol, side string) ([]models.CancelResult, error) {
+	ts.log.Warn("Canceling all orders",
+		zap.String("exchange", string(exType)),
+		zap.String("environment", string(ts.Environment(exType))),
+		zap.String("symbol", symbol.String()),
+		zap.String("side", side),
+	)
+
+	ex, err := ts.getTradingExchange(exType)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := ex.CancelAllOrders(ctx, symbol, side)
+	if err != nil {
+		ts.log.Error("Failed to cancel all orders", zap.Error(err), zap.String("errorCode", errs.Code(err)))
+		return nil, err
+	}
+
+	for i := range results {
+		results[i].Exchange = string(exType)
+	}
+	return results, nil
+}
+
+// CancelAllOrdersEverywhere cancels on every trading exchange in one
+// environment class. With env empty the enabled exchanges must all be
+// production or all be test deployments.
+func (ts *TradingService) CancelAllOrdersEverywhere(ctx context.Context, env models.Environment, symbol models.Symbol, side string) ([]models.ExchangeCancelAllResponse, error) {
+	var trading []exchange.ExchangeType
+	for _, exType := range ts.EnabledExchanges() {
+		if !ts.publicOnly[exType] {
+			trading = append(trading, exType)
+		}
+	}
+	trading, err := ts.sameEnvironment(trading, env)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]models.ExchangeCancelAllResponse, 0, len(trading))
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, exType := range trading {
+		wg.Add(1)
+		go func(exType exchange.ExchangeType) {
+			defer wg.Done()
+
+			resp := models.ExchangeCancelAllResponse{Exchange: string(exType), Environment: ts.Environment(exType)}
+			results, err := ts.CancelAllOrders(ctx, exType, symbol, side)
+			if err != nil {
+				resp.Error = err.Error()
+				resp.ErrorCode = errs.Code(err)
+			}
+			resp.Results = results
+
+			mu.Lock()
+			responses = append(responses, resp)
+			mu.Unlock()
+		}(exType)
+	}
+	wg.Wait()
+
+	sort.Slice(responses, func(i, j int) bool {
+		return responses[i].Exchange < responses[j].Exchange
+	})
+	return responses, nil
+}
+
+// sameEnvironment keeps the exchanges matching env, or, with env empty,
+// checks that production and test deployments are not mixed.
+func (ts *TradingService) sameEnvironment(exTypes []exchange.ExchangeType, env models.Environment) ([]exchange.ExchangeType, error) {
+	if env != "" {
+		var matched []exchange.ExchangeType
+		for _, exType := range exTypes {
+			if ts.Environment(exType).IsProduction() == env.IsProduction() {
+				matched = append(matched, exType)
+			}
+		}
+		return matched, nil
+	}
+
+	var production, test []string
+	for _, exType := range exTypes {
+		if ts.Environment(exType).IsProduction() {
+			production = append(production, string(exType))
+		} else {
+			test = append(test, string(exType))
+		}
+	}
+	if len(production) > 0 && len(test) > 0 {
+		ts.log.Warn("Refusing cross-exchange operation across environments",
+			zap.Strings("production", production),
+			zap.Strings("test", test),
+		)
+		return nil, errs.New(errs.ErrInvalidRequest, "exchanges span production (%s) and test (%s) environments; pass environment to pick one",
+			strings.Join(production, ", "), strings.Join(test, ", "))
+	}
+	return exTypes, nil
+}
+
+func (ts *TradingService) GetFills(ctx context.Context, exType exchange.ExchangeType, symbol models.Symbol, since time.Time) ([]models.Fill, error) {
+	if since.IsZero() {
+		since = time.Now().Add(-defaultFillsLookback)
+	}
+
+	ts.log.Info("Getting fills",
+		zap.String("exchange", string(exType)),
+		zap.String("symbol", symbol.String()),
+		zap.Time("since", since),
+	)
+
+	ex, err := ts.getTradingExchange(exType)
+	if err != nil {
+		return nil, err
+	}
+
+	fills, err := ex.GetFills(ctx, symbol, since)
+	if err != nil {
+		ts.log.Error("Failed to get fills", zap.Error(err), zap.String("errorCode", errs.Code(err)))
+		return nil, err
+	}
+
+	for i := range fills {
+		fills[i].Exchange = string(exType)
+	}
+	return fills, nil
+}
+
+func (ts *TradingService) GetBalance(ctx context.Context, exType exchange.ExchangeType, asset string) (decimal.Decimal, error) {
 	ts.log.Info("Getting balance",
 		zap.String("exchange", string(exType)),
 		zap.String("asset", asset),
 	)
 
-	ex, err, status := ts.getExchange(exType)
+	ex, err := ts.getTradingExchange(exType)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	balance, err := ex.GetBalance(ctx, asset)
+	if err != nil {
+		ts.log.Error("Failed to get balance", zap.Error(err), zap.String("errorCode", errs.Code(err)))
+	}
+
+	return balance, err
+}
+
+func (ts *TradingService) GetBalances(ctx context.Context, exType exchange.ExchangeType) ([]models.Balance, error) {
+	ts.log.Info("Getting balances", zap.String("exchange", string(exType)))
+
+	ex, err := ts.getTradingExchange(exType)
+	if err != nil {
+		return nil, err
+	}
+
+	balances, err := ex.GetBalances(ctx)
 	if err != nil {
-		return 0, err, status
+		ts.log.Error("Failed to get balances", zap.Error(err), zap.String("errorCode", errs.Code(err)))
+		return nil, err
+	}
+
+	if balances == nil {
+		balances = []models.Balance{}
 	}
+	sort.Slice(balances, func(i, j int) bool {
+		return balances[i].Asset < balances[j].Asset
+	})
+	return balances, nil
+}
 
-	balance, err, status := ex.GetBalance(ctx, asset)
+// GetAllBalances snapshots every trading exchange in one environment class,
+// under the same rules as CancelAllOrdersEverywhere.
+func (ts *TradingService) GetAllBalances(ctx context.Context, env models.Environment) (models.BalanceSnapshotResponse, error) {
+	var trading []exchange.ExchangeType
+	for _, exType := range ts.EnabledExchanges() {
+		if !ts.publicOnly[exType] {
+			trading = append(trading, exType)
+		}
+	}
+	trading, err := ts.sameEnvironment(trading, env)
 	if err != nil {
-		ts.log.Error("Failed to get balance", zap.Error(err))
+		return models.BalanceSnapshotResponse{}, err
 	}
+	responses := make([]models.ExchangeBalancesResponse, 0, len(trading))
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, exType := range trading {
+		wg.Add(1)
+		go func(exType exchange.ExchangeType) {
+			defer wg.Done()
 
-	return balance, err, status
+			resp := models.ExchangeBalancesResponse{Exchange: string(exType), Environment: ts.Environment(exType)}
+			balances, err := ts.GetBalances(ctx, exType)
+			if err != nil {
+				resp.Error = err.Error()
+				resp.ErrorCode = errs.Code(err)
+			}
+			resp.Balances = balances
+
+			mu.Lock()
+			responses = append(responses, resp)
+			mu.Unlock()
+		}(exType)
+	}
+	wg.Wait()
+
+	sort.Slice(responses, func(i, j int) bool {
+		return responses[i].Exchange < responses[j].Exchange
+	})
+	return models.BalanceSnapshotResponse{Exchanges: responses, Totals: sumBalances(responses)}, nil
 }
 
-func (ts *TradingService) GetOrderBook(ctx context.Context, exType exchange.ExchangeType, symbol string) (models.OrderBook, error, int) {
+// GetOrderBook returns up to limit levels a side, or the venue's default
+// depth when limit is 0. A positive step groups levels into price buckets
+// of that width: bids round down and asks round up, so a bucket never
+// looks better than the orders in it.
+func (ts *TradingService) GetOrderBook(ctx context.Context, exType exchange.ExchangeType, symbol models.Symbol, limit int, step decimal.Decimal) (models.OrderBook, error) {
 	ts.log.Info("Getting order book",
 		zap.String("exchange", string(exType)),
-		zap.String("symbol", symbol),
+		zap.String("symbol", symbol.String()),
+		zap.Int("limit", limit),
+		zap.Stringer("step", step),
+	)
+
+	if limit < 0 || limit > maxBookLimit {
+		return models.OrderBook{}, errs.New(errs.ErrInvalidRequest, "limit must be between 1 and %d", maxBookLimit)
+	}
+	if step.IsNegative() {
+		return models.OrderBook{}, errs.New(errs.ErrInvalidRequest, "step must be positive")
+	}
+
+	ex, err := ts.getExchange(exType)
+	if err != nil {
+		return models.OrderBook{}, err
+	}
+
+	book, err := ex.GetOrderBook(ctx, symbol, limit)
+	if err != nil {
+		ts.log.Error("Failed to get order book", zap.Error(err), zap.String("errorCode", errs.Code(err)))
+		return models.OrderBook{}, err
+	}
+
+	if step.IsPositive() {
+		book.Bids = aggregateLevels(book.Bids, step, decimal.Decimal.Floor)
+		book.Asks = aggregateLevels(book.Asks, step, decimal.Decimal.Ceil)
+	}
+	if limit > 0 {
+		book.Bids = book.Bids[:min(limit, len(book.Bids))]
+		book.Asks = book.Asks[:min(limit, len(book.Asks))]
+	}
+	if book.Timestamp.IsZero() {
+		book.Timestamp = time.Now()
+	}
+	return book, nil
+}
+
+// SubscribeOrderBook joins the live order book stream for symbol. Streams
+// belong to the venue, so named accounts share their venue's.
+func (ts *TradingService) SubscribeOrderBook(exType exchange.ExchangeType, symbol models.Symbol) (*stream.Subscription, error) {
+	ts.log.Info("Subscribing to order book stream",
+		zap.String("exchange", string(exType)),
+		zap.String("symbol", symbol.String()),
+	)
+
+	if _, err := ts.getExchange(exType); err != nil {
+		return nil, err
+	}
+	if ts.streams == nil {
+		return nil, errs.New(errs.ErrNotSupported, "order book streaming is not configured")
+	}
+	return ts.streams.Subscribe(exType.Venue(), symbol)
+}
+
+// aggregateLevels sums consecutive levels, best first, that round to the
+// same multiple of step.
+func aggregateLevels(levels []models.OrderBookEntry, step decimal.Decimal, round func(decimal.Decimal) decimal.Decimal) []models.OrderBookEntry {
+	buckets := make([]models.OrderBookEntry, 0, len(levels))
+	for _, level := range levels {
+		price := round(level.Price.Div(step)).Mul(step)
+		if n := len(buckets); n > 0 && buckets[n-1].Price.Equal(price) {
+			buckets[n-1].Quantity = buckets[n-1].Quantity.Add(level.Quantity)
+			continue
+		}
+		buckets = append(buckets, models.OrderBookEntry{Price: price, Quantity: level.Quantity})
+	}
+	return buckets
+}
+
+func sumBalances(responses []models.ExchangeBalancesResponse) []models.Balance {
+	byAsset := make(map[string]*models.Balance)
+	for _, resp := range responses {
+		for _, b := range resp.Balances {
+			total, ok := byAsset[b.Asset]
+			if !ok {
+				total = &models.Balance{Asset: b.Asset}
+				byAsset[b.Asset] = total
+			}
+			total.Free = total.Free.Add(b.Free)
+			total.Locked = total.Locked.Add(b.Locked)
+			total.Total = total.Total.Add(b.Total)
+		}
+	}
+
+	totals := make([]models.Balance, 0, len(byAsset))
+	for _, total := range byAsset {
+		totals = append(totals, *total)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		return totals[i].Asset < totals[j].Asset
+	})
+	return totals
+}
+
+func (ts *TradingService) GetTicker(ctx context.Context, exType exchange.ExchangeType, symbol models.Symbol) (models.Ticker, error) {
+	ts.log.Info("Getting ticker",
+		zap.String("exchange", string(exType)),
+		zap.String("symbol", symbol.String()),
+	)
+
+	ex, err := ts.getExchange(exType)
+	if err != nil {
+		return models.Ticker{}, err
+	}
+
+	ticker, err := ex.GetTicker(ctx, symbol)
+	if err != nil {
+		ts.log.Error("Failed to get ticker", zap.Error(err), zap.String("errorCode", errs.Code(err)))
+		return models.Ticker{}, err
+	}
+
+	ticker.Exchange = string(exType)
+	ticker.Symbol = symbol
+	return ticker, nil
+}
+
+// GetTickers fetches several tickers from one exchange in parallel and
+// fails as a whole if any symbol fails.
+func (ts *TradingService) GetTickers(ctx context.Context, exType exchange.ExchangeType, symbols []models.Symbol) ([]models.Ticker, error) {
+	if len(symbols) == 0 || len(symbols) > maxTickerBatch {
+		return nil, errs.New(errs.ErrInvalidRequest, "between 1 and %d symbols are required, got %d", maxTickerBatch, len(symbols))
+	}
+	if _, err := ts.getExchange(exType); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	tickers := make([]models.Ticker, len(symbols))
+	var (
+		once     sync.Once
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	for i, symbol := range symbols {
+		wg.Add(1)
+		go func(i int, symbol models.Symbol) {
+			defer wg.Done()
+
+			ticker, err := ts.GetTicker(ctx, exType, symbol)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			tickers[i] = ticker
+		}(i, symbol)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return tickers, nil
+}
+
+// GetCandles returns up to limit candles opening in [from, to). A zero to
+// means now and a zero from reaches back limit intervals from to.
+func (ts *TradingService) GetCandles(ctx context.Context, exType exchange.ExchangeType, symbol models.Symbol, interval models.CandleInterval, from, to time.Time, limit int) ([]models.Candle, error) {
+	ts.log.Info("Getting candles",
+		zap.String("exchange", string(exType)),
+		zap.String("symbol", symbol.String()),
+		zap.String("interval", string(interval)),
+	)
+
+	if limit == 0 {
+		limit = defaultCandleLimit
+	}
+	if limit < 0 || limit > maxCandleLimit {
+		return nil, errs.New(errs.ErrInvalidRequest, "limit must be between 1 and %d", maxCandleLimit)
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-interval.Duration() * time.Duration(limit))
+	}
+	if !from.Before(to) {
+		return nil, errs.New(errs.ErrInvalidRequest, "from must be before to")
+	}
+
+	ex, err := ts.getExchange(exType)
+	if err != nil {
+		return nil, err
+	}
+
+	candles, err := ex.GetCandles(ctx, symbol, interval, from, to, limit)
+	if err != nil {
+		ts.log.Error("Failed to get candles", zap.Error(err), zap.String("errorCode", errs.Code(err)))
+		return nil, err
+	}
+	if candles == nil {
+		candles = []models.Candle{}
+	}
+	return candles, nil
+}
+
+// GetRecentTrades returns a market's public tape, newest first. Venues that
+// serve a fixed window may return fewer than limit trades.
+func (ts *TradingService) GetRecentTrades(ctx context.Context, exType exchange.ExchangeType, symbol models.Symbol, limit int) ([]models.Trade, error) {
+	ts.log.Info("Getting recent trades",
+		zap.String("exchange", string(exType)),
+		zap.String("symbol", symbol.String()),
 	)
 
-	ex, err, status := ts.getExchange(exType)
+	if limit == 0 {
+		limit = defaultTradeLimit
+	}
+	if limit < 0 || limit > maxTradeLimit {
+		return nil, errs.New(errs.ErrInvalidRequest, "limit must be between 1 and %d", maxTradeLimit)
+	}
+
+	ex, err := ts.getExchange(exType)
 	if err != nil {
-		return models.OrderBook{}, err, status
+		return nil, err
 	}
 
-	book, err, status := ex.GetOrderBook(ctx, symbol)
+	trades, err := ex.GetRecentTrades(ctx, symbol, limit)
 	if err != nil {
-		ts.log.Error("Failed to get order book", zap.Error(err))
+		ts.log.Error("Failed to get recent trades", zap.Error(err), zap.String("errorCode", errs.Code(err)))
+		return nil, err
 	}
 
-	return book, err, status
+	sort.SliceStable(trades, func(i, j int) bool {
+		return trades[i].Timestamp.After(trades[j].Timestamp)
+	})
+	if len(trades) > limit {
+		trades = trades[:limit]
+	}
+	if trades == nil {
+		trades = []models.Trade{}
+	}
+	return trades, nil
+}
+
+func withExchange(orders []models.Order, exType exchange.ExchangeType) []models.Order {
+	for i := range orders {
+		orders[i].Exchange = string(exType)
+	}
+	return orders
 }
@@ -0,0 +1,76 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"eyeOne/models"
+)
+
+const (
+	binanceStreamURL        = "wss://stream.binance.com:9443/ws"
+	binanceTestnetStreamURL = "wss://stream.testnet.binance.vision/ws"
+)
+
+// BinanceFeed reads Binance's diff-depth stream, whose events cover update
+// IDs U through u and are sequenced against a REST snapshot's
+// lastUpdateId.
+type BinanceFeed struct {
+	url string
+}
+
+func NewBinanceFeed(env models.Environment) *BinanceFeed {
+	url := binanceStreamURL
+	if !env.IsProduction() {
+		url = binanceTestnetStreamURL
+	}
+	return &BinanceFeed{url: url}
+}
+
+func (f *BinanceFeed) Sequenced() bool {
+	return true
+}
+
+func (f *BinanceFeed) Run(ctx context.Context, symbol models.Symbol, updates chan<- Update) error {
+	conn, err := dial(ctx, fmt.Sprintf("%s/%s@depth@100ms", f.url, strings.ToLower(symbol.Join(""))))
+	if err != nil {
+		return err
+	}
+
+	return readLoop(ctx, conn, pingFrame, func(msg []byte) error {
+		var event struct {
+			Type      string     `json:"e"`
+			EventTime int64      `json:"E"`
+			First     int64      `json:"U"`
+			Last      int64      `json:"u"`
+			Bids      [][]string `json:"b"`
+			Asks      [][]string `json:"a"`
+		}
+		if err := json.Unmarshal(msg, &event); err != nil {
+			return err
+		}
+		if event.Type != "depthUpdate" {
+			return nil
+		}
+		// A delta that cannot be applied in full ends the connection so the
+		// book is rebuilt from a fresh snapshot.
+		bids, err := models.ConvertToEntries(event.Bids)
+		if err != nil {
+			return err
+		}
+		asks, err := models.ConvertToEntries(event.Asks)
+		if err != nil {
+			return err
+		}
+		return send(ctx, updates, Update{
+			First:     event.First,
+			Last:      event.Last,
+			Bids:      bids,
+			Asks:      asks,
+			Timestamp: time.UnixMilli(event.EventTime),
+		})
+	})
+}
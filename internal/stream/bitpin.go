@@ -0,0 +1,72 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"eyeOne/models"
+)
+
+const bitpinStreamURL = "wss://ws.bitpin.ir"
+
+// BitpinFeed reads Bitpin's order book channel. Bitpin pushes the whole
+// book on every change, so there is nothing to sequence.
+type BitpinFeed struct {
+	url string
+}
+
+func NewBitpinFeed(url string) *BitpinFeed {
+	if url == "" {
+		url = bitpinStreamURL
+	}
+	return &BitpinFeed{url: url}
+}
+
+func (f *BitpinFeed) Sequenced() bool {
+	return false
+}
+
+func (f *BitpinFeed) Run(ctx context.Context, symbol models.Symbol, updates chan<- Update) error {
+	conn, err := dial(ctx, f.url)
+	if err != nil {
+		return err
+	}
+
+	native := symbol.Join("_")
+	subscribe := map[string]any{
+		"method":  "sub_to_order_book",
+		"symbols": []string{native},
+	}
+	conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if err := conn.WriteJSON(subscribe); err != nil {
+		conn.Close()
+		return err
+	}
+
+	return readLoop(ctx, conn, pingFrame, func(msg []byte) error {
+		var event struct {
+			Symbol string     `json:"symbol"`
+			Asks   [][]string `json:"asks"`
+			Bids   [][]string `json:"bids"`
+		}
+		// Acknowledgements and keepalives are not books; skip them.
+		if json.Unmarshal(msg, &event) != nil || event.Symbol != native {
+			return nil
+		}
+		bids, err := models.ConvertToEntries(event.Bids)
+		if err != nil {
+			return err
+		}
+		asks, err := models.ConvertToEntries(event.Asks)
+		if err != nil {
+			return err
+		}
+		return send(ctx, updates, Update{
+			Snapshot:  true,
+			Bids:      bids,
+			Asks:      asks,
+			Timestamp: time.Now(),
+		})
+	})
+}
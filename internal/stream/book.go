@@ -0,0 +1,145 @@
+package stream
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"eyeOne/models"
+)
+
+var errCrossed = errors.New("local book crossed")
+
+// localBook is an order book kept in sync from a snapshot and deltas.
+// Levels are keyed by their canonical price string.
+type localBook struct {
+	bids      map[string]models.OrderBookEntry
+	asks      map[string]models.OrderBookEntry
+	sequence  int64
+	sequenced bool
+	ready     bool
+	timestamp time.Time
+}
+
+func newLocalBook() *localBook {
+	return &localBook{
+		bids: make(map[string]models.OrderBookEntry),
+		asks: make(map[string]models.OrderBookEntry),
+	}
+}
+
+// reset replaces the book with a REST snapshot that deltas are sequenced
+// against.
+func (b *localBook) reset(snapshot models.OrderBook) error {
+	if snapshot.Sequence == 0 {
+		return errors.New("snapshot has no sequence number")
+	}
+	b.replace(snapshot.Bids, snapshot.Asks)
+	b.sequence = snapshot.Sequence
+	b.sequenced = true
+	b.ready = true
+	b.timestamp = snapshot.Timestamp
+	if b.timestamp.IsZero() {
+		b.timestamp = time.Now()
+	}
+	return nil
+}
+
+// apply folds an update into the book. Deltas at or below the current
+// sequence are already included and skipped; a delta that starts past the
+// next expected sequence means messages were lost and the book must be
+// rebuilt.
+func (b *localBook) apply(u Update) error {
+	if u.Snapshot {
+		b.replace(u.Bids, u.Asks)
+		b.sequence = u.Last
+		b.ready = true
+		b.timestamp = u.Timestamp
+		return nil
+	}
+	if !b.ready {
+		return errors.New("delta before snapshot")
+	}
+	if b.sequenced {
+		if u.Last <= b.sequence {
+			return nil
+		}
+		if u.First > b.sequence+1 {
+			return fmt.Errorf("sequence gap: expected %d, got %d", b.sequence+1, u.First)
+		}
+		b.sequence = u.Last
+	}
+
+	setLevels(b.bids, u.Bids)
+	setLevels(b.asks, u.Asks)
+	if !u.Timestamp.IsZero() {
+		b.timestamp = u.Timestamp
+	}
+	return b.check()
+}
+
+func (b *localBook) replace(bids, asks []models.OrderBookEntry) {
+	clear(b.bids)
+	clear(b.asks)
+	setLevels(b.bids, bids)
+	setLevels(b.asks, asks)
+}
+
+func setLevels(side map[string]models.OrderBookEntry, levels []models.OrderBookEntry) {
+	for _, level := range levels {
+		key := level.Price.String()
+		if level.Quantity.IsZero() {
+			delete(side, key)
+			continue
+		}
+		side[key] = level
+	}
+}
+
+// check catches a book that drifted from the venue's without a visible
+// sequence gap.
+func (b *localBook) check() error {
+	bid, ask := best(b.bids, decimal.Decimal.GreaterThan), best(b.asks, decimal.Decimal.LessThan)
+	if bid != nil && ask != nil && !bid.LessThan(*ask) {
+		return errCrossed
+	}
+	return nil
+}
+
+func best(side map[string]models.OrderBookEntry, better func(decimal.Decimal, decimal.Decimal) bool) *decimal.Decimal {
+	var top *decimal.Decimal
+	for _, level := range side {
+		if top == nil || better(level.Price, *top) {
+			price := level.Price
+			top = &price
+		}
+	}
+	return top
+}
+
+// snapshot copies out the best depth levels of each side.
+func (b *localBook) snapshot(depth int) models.OrderBook {
+	return models.OrderBook{
+		Bids:      sortedLevels(b.bids, depth, true),
+		Asks:      sortedLevels(b.asks, depth, false),
+		Sequence:  b.sequence,
+		Timestamp: b.timestamp,
+	}
+}
+
+func sortedLevels(side map[string]models.OrderBookEntry, depth int, descending bool) []models.OrderBookEntry {
+	levels := make([]models.OrderBookEntry, 0, len(side))
+	for _, level := range side {
+		levels = append(levels, level)
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		if descending {
+			return levels[i].Price.GreaterThan(levels[j].Price)
+		}
+		return levels[i].Price.LessThan(levels[j].Price)
+	})
+	return levels[:min(depth, len(levels))]
+}
@@ -0,0 +1,81 @@
+package stream
+
+import (
+	"context"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	pingInterval = 15 * time.Second
+	readTimeout  = time.Minute
+	writeTimeout = 10 * time.Second
+)
+
+func dial(ctx context.Context, url string) (*websocket.Conn, error) {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+	return conn, err
+}
+
+// pingFrame is the keepalive for venues that answer standard ping frames.
+func pingFrame(conn *websocket.Conn) error {
+	return conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout))
+}
+
+// readLoop hands every message on conn to handle until ctx is done, the
+// connection fails or handle returns an error. It sends ping every
+// pingInterval and gives up when nothing, not even a pong, arrives for
+// readTimeout. conn is closed on return.
+func readLoop(ctx context.Context, conn *websocket.Conn, ping func(*websocket.Conn) error, handle func([]byte) error) error {
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(readTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(readTimeout))
+	})
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		ticker := time.NewTicker(pingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				conn.Close()
+				return
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := ping(conn); err != nil {
+					conn.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		conn.SetReadDeadline(time.Now().Add(readTimeout))
+		if err := handle(msg); err != nil {
+			return err
+		}
+	}
+}
+
+func send(ctx context.Context, updates chan<- Update, u Update) error {
+	select {
+	case updates <- u:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
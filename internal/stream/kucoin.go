@@ -0,0 +1,177 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"eyeOne/internal/httpclient"
+	"eyeOne/models"
+)
+
+const (
+	kucoinBaseURL        = "https://api.kucoin.com"
+	kucoinSandboxBaseURL = "https://openapi-sandbox.kucoin.com"
+)
+
+// KucoinFeed reads KuCoin's level2 stream. Every change carries its own
+// sequence number, so each is passed on as a one-sequence delta against
+// the REST snapshot's sequence.
+type KucoinFeed struct {
+	baseURL string
+	client  *httpclient.Client
+}
+
+func NewKucoinFeed(client *httpclient.Client, env models.Environment) *KucoinFeed {
+	baseURL := kucoinBaseURL
+	if !env.IsProduction() {
+		baseURL = kucoinSandboxBaseURL
+	}
+	return &KucoinFeed{baseURL: baseURL, client: client}
+}
+
+func (f *KucoinFeed) Sequenced() bool {
+	return true
+}
+
+func (f *KucoinFeed) Run(ctx context.Context, symbol models.Symbol, updates chan<- Update) error {
+	endpoint, err := f.endpoint(ctx)
+	if err != nil {
+		return err
+	}
+	conn, err := dial(ctx, endpoint)
+	if err != nil {
+		return err
+	}
+
+	subscribe := map[string]any{
+		"id":       strconv.FormatInt(time.Now().UnixNano(), 10),
+		"type":     "subscribe",
+		"topic":    "/market/level2:" + symbol.Join("-"),
+		"response": true,
+	}
+	conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if err := conn.WriteJSON(subscribe); err != nil {
+		conn.Close()
+		return err
+	}
+
+	// KuCoin wants application-level pings; its pong is an ordinary message.
+	ping := func(conn *websocket.Conn) error {
+		conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		return conn.WriteJSON(map[string]string{
+			"id":   strconv.FormatInt(time.Now().UnixNano(), 10),
+			"type": "ping",
+		})
+	}
+
+	return readLoop(ctx, conn, ping, func(msg []byte) error {
+		var event struct {
+			Type    string `json:"type"`
+			Subject string `json:"subject"`
+			Data    struct {
+				Changes struct {
+					Asks [][]string `json:"asks"`
+					Bids [][]string `json:"bids"`
+				} `json:"changes"`
+				Time int64 `json:"time"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(msg, &event); err != nil {
+			return err
+		}
+		switch {
+		case event.Type == "error":
+			return fmt.Errorf("kucoin stream error: %s", msg)
+		case event.Type != "message" || event.Subject != "trade.l2update":
+			return nil
+		}
+
+		bids, err := kucoinChanges(event.Data.Changes.Bids, true)
+		if err != nil {
+			return err
+		}
+		asks, err := kucoinChanges(event.Data.Changes.Asks, false)
+		if err != nil {
+			return err
+		}
+		changes := append(bids, asks...)
+		sort.Slice(changes, func(i, j int) bool {
+			return changes[i].First < changes[j].First
+		})
+		for _, u := range changes {
+			u.Timestamp = time.UnixMilli(event.Data.Time)
+			if err := send(ctx, updates, u); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// kucoinChanges converts [price, size, sequence] rows. A zero price only
+// advances the sequence. A malformed row is an error, since skipping it
+// would leave the book out of step with the venue.
+func kucoinChanges(rows [][]string, bid bool) ([]Update, error) {
+	changes := make([]Update, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 3 {
+			return nil, fmt.Errorf("invalid kucoin change %q", row)
+		}
+		seq, err := strconv.ParseInt(row[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kucoin change sequence %q: %w", row[2], err)
+		}
+		u := Update{First: seq, Last: seq}
+		if row[0] != "0" {
+			levels, err := models.ConvertToEntries([][]string{row[:2]})
+			if err != nil {
+				return nil, err
+			}
+			if bid {
+				u.Bids = levels
+			} else {
+				u.Asks = levels
+			}
+		}
+		changes = append(changes, u)
+	}
+	return changes, nil
+}
+
+// endpoint requests a public connection token.
+func (f *KucoinFeed) endpoint(ctx context.Context) (string, error) {
+	body, _, err := f.client.PostJSON(ctx, f.baseURL+"/api/v1/bullet-public", struct{}{}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var res struct {
+		Code string `json:"code"`
+		Data struct {
+			Token   string `json:"token"`
+			Servers []struct {
+				Endpoint string `json:"endpoint"`
+			} `json:"instanceServers"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return "", err
+	}
+	if res.Code != "200000" || len(res.Data.Servers) == 0 {
+		return "", errors.New("kucoin returned no stream server")
+	}
+
+	params := url.Values{
+		"token":     {res.Data.Token},
+		"connectId": {strconv.FormatInt(time.Now().UnixNano(), 10)},
+	}
+	return res.Data.Servers[0].Endpoint + "?" + params.Encode(), nil
+}
@@ -0,0 +1,238 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"eyeOne/internal/errs"
+	"eyeOne/internal/exchange"
+	"eyeOne/models"
+)
+
+const (
+	// MaxDepth is the most levels a side that subscribers are sent.
+	MaxDepth = 1000
+
+	snapshotDepth = 1000
+	updateBuffer  = 4096
+	minBackoff    = time.Second
+	maxBackoff    = time.Minute
+)
+
+// Update is one message from a venue's depth feed. Levels carry absolute
+// quantities and a zero quantity removes the level. A Snapshot update
+// replaces the whole book; any other update is a delta covering sequence
+// numbers First through Last.
+type Update struct {
+	Snapshot  bool
+	First     int64
+	Last      int64
+	Bids      []models.OrderBookEntry
+	Asks      []models.OrderBookEntry
+	Timestamp time.Time
+}
+
+// Feed is a venue's depth stream.
+type Feed interface {
+	// Run connects, subscribes to symbol and sends updates until ctx is
+	// done or the connection fails.
+	Run(ctx context.Context, symbol models.Symbol, updates chan<- Update) error
+	// Sequenced reports whether the feed only sends deltas, which need a
+	// REST snapshot to start from.
+	Sequenced() bool
+}
+
+type venue struct {
+	feed Feed
+	rest exchange.Exchange
+}
+
+type bookKey struct {
+	venue  exchange.ExchangeType
+	symbol models.Symbol
+}
+
+// Manager keeps one upstream stream per venue and symbol while anyone is
+// subscribed to it and fans the maintained book out to subscribers.
+type Manager struct {
+	log    *zap.Logger
+	venues map[exchange.ExchangeType]venue
+
+	mu   sync.Mutex
+	hubs map[bookKey]*hub
+}
+
+type hub struct {
+	subs   map[*Subscription]struct{}
+	last   *models.OrderBook
+	cancel context.CancelFunc
+}
+
+// Subscription delivers the latest book to one subscriber. A slow reader
+// skips intermediate books rather than falling behind. C is closed when
+// the subscription ends.
+type Subscription struct {
+	C  <-chan models.OrderBook
+	ch chan models.OrderBook
+
+	manager *Manager
+	key     bookKey
+	once    sync.Once
+}
+
+func NewManager(logger *zap.Logger) *Manager {
+	return &Manager{
+		log:    logger,
+		venues: make(map[exchange.ExchangeType]venue),
+		hubs:   make(map[bookKey]*hub),
+	}
+}
+
+// Register enables streaming for a venue. rest supplies the snapshots that
+// sequenced feeds start from.
+func (m *Manager) Register(venueType exchange.ExchangeType, feed Feed, rest exchange.Exchange) {
+	m.venues[venueType] = venue{feed: feed, rest: rest}
+}
+
+func (m *Manager) Supports(venueType exchange.ExchangeType) bool {
+	_, ok := m.venues[venueType]
+	return ok
+}
+
+// Subscribe joins the book stream for symbol, starting it if needed. A
+// late subscriber gets the current book straight away.
+func (m *Manager) Subscribe(venueType exchange.ExchangeType, symbol models.Symbol) (*Subscription, error) {
+	v, ok := m.venues[venueType]
+	if !ok {
+		return nil, errs.New(errs.ErrNotSupported, "order book streaming is not supported on %s", venueType)
+	}
+
+	ch := make(chan models.OrderBook, 1)
+	sub := &Subscription{C: ch, ch: ch, manager: m, key: bookKey{venue: venueType, symbol: symbol}}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.hubs[sub.key]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		h = &hub{subs: make(map[*Subscription]struct{}), cancel: cancel}
+		m.hubs[sub.key] = h
+		go m.run(ctx, sub.key, h, v)
+	}
+	h.subs[sub] = struct{}{}
+	if h.last != nil {
+		ch <- *h.last
+	}
+	return sub, nil
+}
+
+// Close leaves the stream and stops it once nobody is left.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		m := s.manager
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if h, ok := m.hubs[s.key]; ok {
+			delete(h.subs, s)
+			if len(h.subs) == 0 {
+				h.cancel()
+				delete(m.hubs, s.key)
+			}
+		}
+		close(s.ch)
+	})
+}
+
+// publish hands book to h's subscribers. Once the last subscriber has left,
+// a new Subscribe may have started another hub under the same key; a book
+// from the old stream must not reach it.
+func (m *Manager) publish(key bookKey, h *hub, book models.OrderBook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.hubs[key] != h {
+		return
+	}
+	h.last = &book
+	for sub := range h.subs {
+		select {
+		case <-sub.ch:
+		default:
+		}
+		sub.ch <- book
+	}
+}
+
+// run keeps the book for key synchronized, rebuilding it from scratch with
+// backoff whenever the feed drops or the book falls out of sync.
+func (m *Manager) run(ctx context.Context, key bookKey, h *hub, v venue) {
+	log := m.log.With(zap.String("exchange", string(key.venue)), zap.String("symbol", key.symbol.String()))
+	log.Info("Starting order book stream")
+
+	backoff := minBackoff
+	for {
+		synced, err := m.sync(ctx, key, h, v)
+		if ctx.Err() != nil {
+			log.Info("Stopped order book stream")
+			return
+		}
+		if synced {
+			backoff = minBackoff
+		}
+		log.Warn("Order book stream lost, resyncing", zap.Error(err), zap.Duration("backoff", backoff))
+
+		select {
+		case <-ctx.Done():
+			log.Info("Stopped order book stream")
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// sync runs one connection. For sequenced feeds the stream is opened first
+// and the snapshot fetched once the first delta has arrived, so buffered
+// deltas bridge the snapshot to the live stream.
+func (m *Manager) sync(ctx context.Context, key bookKey, h *hub, v venue) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	updates := make(chan Update, updateBuffer)
+	done := make(chan error, 1)
+	go func() {
+		done <- v.feed.Run(ctx, key.symbol, updates)
+	}()
+
+	book := newLocalBook()
+	synced := false
+	for {
+		select {
+		case <-ctx.Done():
+			return synced, ctx.Err()
+		case err := <-done:
+			if err == nil {
+				err = errors.New("feed closed")
+			}
+			return synced, err
+		case u := <-updates:
+			if v.feed.Sequenced() && !book.ready {
+				snapshot, err := v.rest.GetOrderBook(ctx, key.symbol, snapshotDepth)
+				if err != nil {
+					return false, err
+				}
+				if err := book.reset(snapshot); err != nil {
+					return false, err
+				}
+			}
+			if err := book.apply(u); err != nil {
+				return synced, err
+			}
+			synced = true
+			m.publish(key, h, book.snapshot(MaxDepth))
+		}
+	}
+}
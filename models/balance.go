@@ -0,0 +1,12 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+// Balance is one asset in an account snapshot. Total is Free plus Locked,
+// where Locked is held by open orders or withdrawals.
+type Balance struct {
+	Asset  string          `json:"asset"`
+	Free   decimal.Decimal `json:"free"`
+	Locked decimal.Decimal `json:"locked"`
+	Total  decimal.Decimal `json:"total"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// CandleInterval is the canonical candle width. Adapters translate it to
+// their venue's notation.
+type CandleInterval string
+
+const (
+	CandleInterval1m  CandleInterval = "1m"
+	CandleInterval5m  CandleInterval = "5m"
+	CandleInterval15m CandleInterval = "15m"
+	CandleInterval1h  CandleInterval = "1h"
+	CandleInterval4h  CandleInterval = "4h"
+	CandleInterval1d  CandleInterval = "1d"
+)
+
+var candleIntervals = map[CandleInterval]time.Duration{
+	CandleInterval1m:  time.Minute,
+	CandleInterval5m:  5 * time.Minute,
+	CandleInterval15m: 15 * time.Minute,
+	CandleInterval1h:  time.Hour,
+	CandleInterval4h:  4 * time.Hour,
+	CandleInterval1d:  24 * time.Hour,
+}
+
+func ParseCandleInterval(value string) (CandleInterval, error) {
+	interval := CandleInterval(strings.ToLower(strings.TrimSpace(value)))
+	if _, ok := candleIntervals[interval]; !ok {
+		return "", fmt.Errorf("interval must be one of 1m, 5m, 15m, 1h, 4h, 1d (got: %s)", value)
+	}
+	return interval, nil
+}
+
+func (i CandleInterval) Duration() time.Duration {
+	return candleIntervals[i]
+}
+
+// Minutes is the width in minutes, which is how most venues name intervals.
+func (i CandleInterval) Minutes() int {
+	return int(i.Duration() / time.Minute)
+}
+
+// Candle is one OHLCV bar. Volume is in the base asset.
+type Candle struct {
+	OpenTime time.Time       `json:"openTime"`
+	Open     decimal.Decimal `json:"open"`
+	High     decimal.Decimal `json:"high"`
+	Low      decimal.Decimal `json:"low"`
+	Close    decimal.Decimal `json:"close"`
+	Volume   decimal.Decimal `json:"volume"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+type Market struct {
+	Symbol      Symbol          `json:"symbol"`
+	Tradable    bool            `json:"tradable"`
+	TickSize    decimal.Decimal `json:"tickSize"`
+	StepSize    decimal.Decimal `json:"stepSize"`
+	MinQuantity decimal.Decimal `json:"minQuantity"`
+	MaxQuantity decimal.Decimal `json:"maxQuantity"`
+	MinNotional decimal.Decimal `json:"minNotional"`
+}
+
+// PrecisionStep converts a number of decimal places into the smallest
+// increment it allows, e.g. 2 becomes 0.01.
+func PrecisionStep(places int32) decimal.Decimal {
+	return decimal.New(1, -places)
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type OrderStatus string
+
+const (
+	OrderStatusNew             OrderStatus = "new"
+	OrderStatusPartiallyFilled OrderStatus = "partially-filled"
+	OrderStatusFilled          OrderStatus = "filled"
+	OrderStatusCanceled        OrderStatus = "canceled"
+	OrderStatusRejected        OrderStatus = "rejected"
+)
+
+type Order struct {
+	OrderID        string          `json:"orderId"`
+	Exchange       string          `json:"exchange"`
+	Symbol         Symbol          `json:"symbol"`
+	Side           string          `json:"side"`
+	Type           string          `json:"type"`
+	Status         OrderStatus     `json:"status"`
+	Price          decimal.Decimal `json:"price"`
+	Quantity       decimal.Decimal `json:"quantity"`
+	FilledQuantity decimal.Decimal `json:"filledQuantity"`
+	AvgFillPrice   decimal.Decimal `json:"avgFillPrice"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	UpdatedAt      time.Time       `json:"updatedAt"`
+}
+
+func (s OrderStatus) IsOpen() bool {
+	return s == OrderStatusNew || s == OrderStatusPartiallyFilled
+}
+
+const DefaultPageLimit = 50
+
+type OrderHistoryQuery struct {
+	Symbol    Symbol
+	StartTime time.Time
+	EndTime   time.Time
+	Page      int
+	Limit     int
+}
+
+func (q OrderHistoryQuery) WithDefaults() OrderHistoryQuery {
+	if q.Page <= 0 {
+		q.Page = 1
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultPageLimit
+	}
+	return q
+}
+
+type CancelResult struct {
+	OrderID   string `json:"orderId"`
+	Exchange  string `json:"exchange,omitempty"`
+	Symbol    Symbol `json:"symbol"`
+	Canceled  bool   `json:"canceled"`
+	Error     string `json:"error,omitempty"`
+	ErrorCode string `json:"errorCode,omitempty"`
+}
+
+type Liquidity string
+
+const (
+	LiquidityMaker Liquidity = "maker"
+	LiquidityTaker Liquidity = "taker"
+)
+
+type Fill struct {
+	TradeID   string          `json:"tradeId"`
+	OrderID   string          `json:"orderId"`
+	Exchange  string          `json:"exchange"`
+	Symbol    Symbol          `json:"symbol"`
+	Side      string          `json:"side"`
+	Price     decimal.Decimal `json:"price"`
+	Quantity  decimal.Decimal `json:"quantity"`
+	Fee       decimal.Decimal `json:"fee"`
+	FeeAsset  string          `json:"feeAsset"`
+	Liquidity Liquidity       `json:"liquidity"`
+	Timestamp time.Time       `json:"timestamp"`
+}
@@ -1,11 +1,22 @@
 package models
 
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// OrderBook is a depth snapshot, best levels first. Sequence is the venue's
+// update ID where it publishes one and 0 otherwise; Timestamp is the
+// venue's snapshot time, or the time of retrieval when it reports none.
 type OrderBook struct {
-	Asks []OrderBookEntry
-	Bids []OrderBookEntry
+	Asks      []OrderBookEntry
+	Bids      []OrderBookEntry
+	Sequence  int64
+	Timestamp time.Time
 }
 
 type OrderBookEntry struct {
-	Price    float64
-	Quantity float64
+	Price    decimal.Decimal
+	Quantity decimal.Decimal
 }
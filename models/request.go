@@ -4,20 +4,19 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
-	"strconv"
-	"strings"
 
+	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 
 	"eyeOne/pkg/logger"
 )
 
 type CreateOrderRequest struct {
-	Symbol    string  `json:"symbol" binding:"required"`
-	Side      string  `json:"side" binding:"required"`
-	OrderType string  `json:"orderType" binding:"required"`
-	Quantity  float64 `json:"quantity" binding:"required"`
-	Price     float64 `json:"price" binding:"required"`
+	Symbol    Symbol          `json:"symbol" binding:"required"`
+	Side      string          `json:"side" binding:"required"`
+	OrderType string          `json:"orderType" binding:"required"`
+	Quantity  decimal.Decimal `json:"quantity"`
+	Price     decimal.Decimal `json:"price"`
 }
 
 type CancelOrderRequest struct {
@@ -30,40 +29,71 @@ type GetBalanceRequest struct {
 }
 
 type GetOrderBookRequest struct {
-	Symbol string `json:"symbol" binding:"required"`
-	Limit  int    `json:"limit" binding:"omitempty"`
+	Limit int    `form:"limit" binding:"omitempty,min=1"`
+	Step  string `form:"step"`
 }
 
-var (
-	validSymbolRegex = regexp.MustCompile(`^[A-Z0-9_]{6,20}$`)
-	validAssetRegex  = regexp.MustCompile(`^[A-Z0-9]{2,10}$`)
-)
+type CancelAllOrdersRequest struct {
+	Symbol      string `form:"symbol"`
+	Side        string `form:"side" binding:"omitempty,oneof=buy sell BUY SELL"`
+	Environment string `form:"environment"`
+}
+
+type ListBalancesRequest struct {
+	Environment string `form:"environment"`
+}
 
-func ValidateSymbol(symbol, exchange string) error {
+type ListTickersRequest struct {
+	Symbols string `form:"symbols" binding:"required"`
+}
+
+type ListCandlesRequest struct {
+	Interval string `form:"interval" binding:"required"`
+	From     int64  `form:"from" binding:"omitempty,min=0"`
+	To       int64  `form:"to" binding:"omitempty,min=0"`
+	Limit    int    `form:"limit" binding:"omitempty,min=1"`
+}
+
+type StreamOrderBookRequest struct {
+	Limit int `form:"limit" binding:"omitempty,min=1"`
+}
+
+type ListTradesRequest struct {
+	Limit int `form:"limit" binding:"omitempty,min=1"`
+}
+
+type ListFillsRequest struct {
+	Symbol string `form:"symbol"`
+	Since  int64  `form:"since" binding:"omitempty,min=0"`
+}
+
+type ListOrdersRequest struct {
+	Symbol string `form:"symbol"`
+	From   int64  `form:"from" binding:"omitempty,min=0"`
+	To     int64  `form:"to" binding:"omitempty,min=0"`
+	Page   int    `form:"page" binding:"omitempty,min=1"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=500"`
+}
+
+type SetExchangeEnabledRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+var validAssetRegex = regexp.MustCompile(`^[A-Z0-9]{2,10}$`)
+
+func ValidateOrderAmounts(quantity, price decimal.Decimal) error {
 	log := logger.GetLogger()
 
-	if symbol == "" {
-		err := errors.New("symbol is required")
-		log.Warn("Validation error", zap.String("field", "symbol"), zap.Error(err), zap.String("exchange", exchange))
+	if !quantity.IsPositive() {
+		err := fmt.Errorf("quantity must be a positive decimal (got: %s)", quantity)
+		log.Warn("Validation error", zap.String("field", "quantity"), zap.Error(err))
 		return err
 	}
-
-	switch strings.ToLower(exchange) {
-	case "bitpin":
-		bitpinRegex := regexp.MustCompile(`^[A-Z0-9]+_[A-Z0-9]+$`)
-		if !bitpinRegex.MatchString(symbol) {
-			err := fmt.Errorf("bitpin symbol format invalid: must be UPPERCASE_ASSET1_ASSET2 (got: %s)", symbol)
-			log.Warn("Validation error", zap.String("field", "symbol"), zap.Error(err), zap.String("exchange", exchange))
-			return err
-		}
-	default:
-		if !validSymbolRegex.MatchString(symbol) {
-			err := fmt.Errorf("symbol must be uppercase letters or digits, 6 to 20 characters (got: %s)", symbol)
-			log.Warn("Validation error", zap.String("field", "symbol"), zap.Error(err), zap.String("exchange", exchange))
-			return err
-		}
+	if !price.IsPositive() {
+		err := fmt.Errorf("price must be a positive decimal (got: %s)", price)
+		log.Warn("Validation error", zap.String("field", "price"), zap.Error(err))
+		return err
 	}
-
 	return nil
 }
 
@@ -83,21 +113,27 @@ func ValidateAsset(asset string) error {
 	return nil
 }
 
-func ConvertToEntries(entries [][]string) []OrderBookEntry {
+// ConvertToEntries parses [price, quantity] levels. A malformed level fails
+// the whole conversion: dropping it would leave a book that no longer
+// matches the venue.
+func ConvertToEntries(entries [][]string) ([]OrderBookEntry, error) {
 	result := make([]OrderBookEntry, 0, len(entries))
 	for _, pair := range entries {
 		if len(pair) != 2 {
-			continue
+			return nil, fmt.Errorf("invalid book level %q", pair)
+		}
+		price, err := decimal.NewFromString(pair[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid book level price %q: %w", pair[0], err)
 		}
-		price, err1 := strconv.ParseFloat(pair[0], 64)
-		qty, err2 := strconv.ParseFloat(pair[1], 64)
-		if err1 != nil || err2 != nil {
-			continue
+		qty, err := decimal.NewFromString(pair[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid book level quantity %q: %w", pair[1], err)
 		}
 		result = append(result, OrderBookEntry{
 			Price:    price,
 			Quantity: qty,
 		})
 	}
-	return result
+	return result, nil
 }
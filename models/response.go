@@ -1,5 +1,13 @@
 package models
 
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
 type SuccessResponse struct {
 	StatusCode int    `json:"statusCode"`
 	Data       any    `json:"data,omitempty"`
@@ -9,6 +17,7 @@ type SuccessResponse struct {
 
 type ErrorResponse struct {
 	StatusCode int    `json:"statusCode"`
+	ErrorCode  string `json:"errorCode"`
 	Message    string `json:"message"`
 	Timestamp  int64  `json:"timestamp"`
 }
@@ -20,21 +29,498 @@ type ErrorPayload struct {
 }
 
 type OrderDataResponse struct {
-	OrderID  string  `json:"orderId"`
+	OrderID     string          `json:"orderId"`
+	Exchange    string          `json:"exchange"`
+	Environment Environment     `json:"environment"`
+	Symbol      Symbol          `json:"symbol"`
+	Side        string          `json:"side"`
+	Type        string          `json:"type"`
+	Quantity    decimal.Decimal `json:"quantity"`
+	Price       decimal.Decimal `json:"price"`
+}
+
+type OrderListResponse struct {
+	Orders []Order `json:"orders"`
+	Page   int     `json:"page,omitempty"`
+	Limit  int     `json:"limit,omitempty"`
+}
+
+type CancelAllResponse struct {
+	Results  []CancelResult `json:"results"`
+	Canceled int            `json:"canceled"`
+	Failed   int            `json:"failed"`
+}
+
+type ExchangeCancelAllResponse struct {
+	Exchange    string         `json:"exchange"`
+	Environment Environment    `json:"environment"`
+	Results     []CancelResult `json:"results"`
+	Error       string         `json:"error,omitempty"`
+	ErrorCode   string         `json:"errorCode,omitempty"`
+}
+
+type TickerListResponse struct {
+	Tickers []Ticker `json:"tickers"`
+}
+
+type CandleListResponse struct {
+	Interval CandleInterval `json:"interval"`
+	Candles  []Candle       `json:"candles"`
+}
+
+// OrderBookStreamMessage is one book pushed over the order book WebSocket.
+type OrderBookStreamMessage struct {
+	Exchange string `json:"exchange"`
+	Symbol   Symbol `json:"symbol"`
+	OrderBook
+}
+
+type TradeListResponse struct {
 	Exchange string  `json:"exchange"`
-	Symbol   string  `json:"symbol"`
-	Side     string  `json:"side"`
-	Type     string  `json:"type"`
-	Quantity float64 `json:"quantity"`
-	Price    float64 `json:"price"`
+	Symbol   Symbol  `json:"symbol"`
+	Trades   []Trade `json:"trades"`
+}
+
+type FillListResponse struct {
+	Fills []Fill `json:"fills"`
+}
+
+type MarketListResponse struct {
+	Markets   []Market `json:"markets"`
+	UpdatedAt int64    `json:"updatedAt"`
+}
+
+type ExchangeMode string
+
+const (
+	ExchangeModeTrading ExchangeMode = "trading"
+	ExchangeModePublic  ExchangeMode = "public-only"
+)
+
+// Environment is the venue deployment an exchange is wired to. Testnet
+// and sandbox are the names venues use for their test deployments.
+type Environment string
+
+const (
+	EnvironmentProduction Environment = "production"
+	EnvironmentTestnet    Environment = "testnet"
+	EnvironmentSandbox    Environment = "sandbox"
+)
+
+func ParseEnvironment(value string) (Environment, error) {
+	switch env := Environment(strings.ToLower(strings.TrimSpace(value))); env {
+	case "":
+		return EnvironmentProduction, nil
+	case EnvironmentProduction, EnvironmentTestnet, EnvironmentSandbox:
+		return env, nil
+	}
+	return "", fmt.Errorf("unknown environment %q, expected production, testnet or sandbox", value)
+}
+
+func (e Environment) IsProduction() bool {
+	return e == "" || e == EnvironmentProduction
+}
+
+type ExchangeStatus struct {
+	Exchange    string       `json:"exchange"`
+	Enabled     bool         `json:"enabled"`
+	Mode        ExchangeMode `json:"mode"`
+	Environment Environment  `json:"environment"`
+	// Unsupported lists operations the exchange answers with 501.
+	Unsupported []string `json:"unsupported,omitempty"`
 }
 
 type BalanceDataResponse struct {
-	Asset   string  `json:"asset"`
-	Balance float64 `json:"balance"`
+	Asset   string          `json:"asset"`
+	Balance decimal.Decimal `json:"balance"`
+}
+
+type ExchangeBalancesResponse struct {
+	Exchange    string      `json:"exchange"`
+	Environment Environment `json:"environment"`
+	Balances    []Balance   `json:"balances"`
+	Error       string      `json:"error,omitempty"`
+	ErrorCode   string      `json:"errorCode,omitempty"`
+}
+
+// BalanceSnapshotResponse holds every exchange's balances and their sum
+// per asset over the exchanges that answered.
+type BalanceSnapshotResponse struct {
+	Exchanges []ExchangeBalancesResponse `json:"exchanges"`
+	Totals    []Balance                  `json:"totals"`
 }
 
 type BitpinOrderBookResponse struct {
 	Asks [][]string `json:"asks"`
 	Bids [][]string `json:"bids"`
 }
+
+type BitpinOrderResponse struct {
+	ID                int64  `json:"id"`
+	Symbol            string `json:"symbol"`
+	Type              string `json:"type"`
+	Side              string `json:"side"`
+	Price             string `json:"price"`
+	BaseAmount        string `json:"base_amount"`
+	QuoteAmount       string `json:"quote_amount"`
+	DealedBaseAmount  string `json:"dealed_base_amount"`
+	DealedQuoteAmount string `json:"dealed_quote_amount"`
+	State             string `json:"state"`
+	CreatedAt         string `json:"created_at"`
+	ClosedAt          string `json:"closed_at"`
+}
+
+type BitpinFillResponse struct {
+	ID              int64  `json:"id"`
+	OrderID         int64  `json:"order_id"`
+	Symbol          string `json:"symbol"`
+	Side            string `json:"side"`
+	Price           string `json:"price"`
+	BaseAmount      string `json:"base_amount"`
+	QuoteAmount     string `json:"quote_amount"`
+	Commission      string `json:"commission"`
+	CommissionAsset string `json:"commission_asset"`
+	IsMaker         bool   `json:"is_maker"`
+	CreatedAt       string `json:"created_at"`
+}
+
+type BitpinMarketResponse struct {
+	Symbol               string `json:"symbol"`
+	Base                 string `json:"base"`
+	Quote                string `json:"quote"`
+	Tradable             bool   `json:"tradable"`
+	PricePrecision       int32  `json:"price_precision"`
+	BaseAmountPrecision  int32  `json:"base_amount_precision"`
+	QuoteAmountPrecision int32  `json:"quote_amount_precision"`
+	MinBaseAmount        string `json:"min_base_amount"`
+	MinQuoteAmount       string `json:"min_quote_amount"`
+}
+
+type WallexOrderBookEntry struct {
+	Price    decimal.Decimal `json:"price"`
+	Quantity decimal.Decimal `json:"quantity"`
+}
+
+type WallexOrderBookResponse struct {
+	Ask []WallexOrderBookEntry `json:"ask"`
+	Bid []WallexOrderBookEntry `json:"bid"`
+}
+
+type WallexOrderResponse struct {
+	ClientOrderID string `json:"clientOrderId"`
+	Symbol        string `json:"symbol"`
+	Type          string `json:"type"`
+	Side          string `json:"side"`
+	Price         string `json:"price"`
+	OrigQty       string `json:"origQty"`
+	ExecutedQty   string `json:"executedQty"`
+	ExecutedSum   string `json:"executedSum"`
+	Status        string `json:"status"`
+	TransactTime  string `json:"transactTime"`
+	UpdatedAt     string `json:"updatedAt"`
+}
+
+type WallexTradeResponse struct {
+	ID            string `json:"id"`
+	ClientOrderID string `json:"clientOrderId"`
+	Symbol        string `json:"symbol"`
+	Price         string `json:"price"`
+	Quantity      string `json:"quantity"`
+	Fee           string `json:"fee"`
+	FeeAsset      string `json:"feeAsset"`
+	IsBuyer       bool   `json:"isBuyer"`
+	IsMaker       bool   `json:"isMaker"`
+	Timestamp     string `json:"timestamp"`
+}
+
+type BitpinWalletResponse struct {
+	Asset   string `json:"asset"`
+	Balance string `json:"balance"`
+	Frozen  string `json:"frozen"`
+}
+
+// UDFHistoryResponse is a TradingView UDF candle history. Status is "ok",
+// "no_data" or "error".
+type UDFHistoryResponse struct {
+	Status string            `json:"s"`
+	Error  string            `json:"errmsg"`
+	Time   []int64           `json:"t"`
+	Open   []decimal.Decimal `json:"o"`
+	High   []decimal.Decimal `json:"h"`
+	Low    []decimal.Decimal `json:"l"`
+	Close  []decimal.Decimal `json:"c"`
+	Volume []decimal.Decimal `json:"v"`
+}
+
+type BitpinTickerResponse struct {
+	Symbol           string  `json:"symbol"`
+	Price            string  `json:"price"`
+	DailyChangePrice string  `json:"daily_change_price"`
+	Low              string  `json:"low"`
+	High             string  `json:"high"`
+	Timestamp        float64 `json:"timestamp"`
+}
+
+type BitpinMatchResponse struct {
+	ID         string  `json:"id"`
+	Price      string  `json:"price"`
+	BaseAmount string  `json:"base_amount"`
+	Side       string  `json:"side"`
+	Time       float64 `json:"time"`
+}
+
+type WallexBalanceResponse struct {
+	Asset  string `json:"asset"`
+	Value  string `json:"value"`
+	Locked string `json:"locked"`
+}
+
+type WallexMarketResponse struct {
+	Symbol      string          `json:"symbol"`
+	BaseAsset   string          `json:"baseAsset"`
+	QuoteAsset  string          `json:"quoteAsset"`
+	StepSize    int32           `json:"stepSize"`
+	TickSize    int32           `json:"tickSize"`
+	MinQty      decimal.Decimal `json:"minQty"`
+	MaxQty      decimal.Decimal `json:"maxQty"`
+	MinNotional decimal.Decimal `json:"minNotional"`
+	Stats       json.RawMessage `json:"stats"`
+}
+
+type NobitexOrderBookResponse struct {
+	Status     string     `json:"status"`
+	LastUpdate int64      `json:"lastUpdate"`
+	Asks       [][]string `json:"asks"`
+	Bids       [][]string `json:"bids"`
+}
+
+type NobitexOrderResponse struct {
+	ID            int64  `json:"id"`
+	Type          string `json:"type"`
+	Execution     string `json:"execution"`
+	SrcCurrency   string `json:"srcCurrency"`
+	DstCurrency   string `json:"dstCurrency"`
+	Price         string `json:"price"`
+	Amount        string `json:"amount"`
+	MatchedAmount string `json:"matchedAmount"`
+	TotalPrice    string `json:"totalPrice"`
+	Status        string `json:"status"`
+	CreatedAt     string `json:"created_at"`
+}
+
+type NobitexTradeResponse struct {
+	ID          int64  `json:"id"`
+	OrderID     int64  `json:"orderId"`
+	SrcCurrency string `json:"srcCurrency"`
+	DstCurrency string `json:"dstCurrency"`
+	Type        string `json:"type"`
+	Price       string `json:"price"`
+	Amount      string `json:"amount"`
+	Fee         string `json:"fee"`
+	Timestamp   string `json:"timestamp"`
+}
+
+type NobitexWalletResponse struct {
+	Currency       string `json:"currency"`
+	Balance        string `json:"balance"`
+	BlockedBalance string `json:"blockedBalance"`
+	ActiveBalance  string `json:"activeBalance"`
+}
+
+type WallexPublicTradeResponse struct {
+	Price      string `json:"price"`
+	Quantity   string `json:"quantity"`
+	IsBuyOrder bool   `json:"isBuyOrder"`
+	Timestamp  string `json:"timestamp"`
+}
+
+type NobitexStatsResponse struct {
+	BestBuy   string `json:"bestBuy"`
+	BestSell  string `json:"bestSell"`
+	Latest    string `json:"latest"`
+	DayOpen   string `json:"dayOpen"`
+	DayHigh   string `json:"dayHigh"`
+	DayLow    string `json:"dayLow"`
+	VolumeSrc string `json:"volumeSrc"`
+	VolumeDst string `json:"volumeDst"`
+}
+
+type NobitexPublicTradeResponse struct {
+	Time   int64  `json:"time"`
+	Price  string `json:"price"`
+	Volume string `json:"volume"`
+	Type   string `json:"type"`
+}
+
+type OKXTickerResponse struct {
+	Last      string `json:"last"`
+	BidPx     string `json:"bidPx"`
+	AskPx     string `json:"askPx"`
+	Open24h   string `json:"open24h"`
+	High24h   string `json:"high24h"`
+	Low24h    string `json:"low24h"`
+	Vol24h    string `json:"vol24h"`
+	VolCcy24h string `json:"volCcy24h"`
+	Ts        string `json:"ts"`
+}
+
+type OKXTradeResponse struct {
+	TradeID string `json:"tradeId"`
+	Px      string `json:"px"`
+	Sz      string `json:"sz"`
+	Side    string `json:"side"`
+	Ts      string `json:"ts"`
+}
+
+type OKXOrderBookResponse struct {
+	Asks [][]string `json:"asks"`
+	Bids [][]string `json:"bids"`
+	Ts   string     `json:"ts"`
+}
+
+type OKXOrderResponse struct {
+	InstID    string `json:"instId"`
+	OrdID     string `json:"ordId"`
+	Side      string `json:"side"`
+	OrdType   string `json:"ordType"`
+	State     string `json:"state"`
+	Px        string `json:"px"`
+	Sz        string `json:"sz"`
+	AccFillSz string `json:"accFillSz"`
+	AvgPx     string `json:"avgPx"`
+	CTime     string `json:"cTime"`
+	UTime     string `json:"uTime"`
+}
+
+type OKXFillResponse struct {
+	InstID   string `json:"instId"`
+	TradeID  string `json:"tradeId"`
+	OrdID    string `json:"ordId"`
+	Side     string `json:"side"`
+	FillPx   string `json:"fillPx"`
+	FillSz   string `json:"fillSz"`
+	Fee      string `json:"fee"`
+	FeeCcy   string `json:"feeCcy"`
+	ExecType string `json:"execType"`
+	Ts       string `json:"ts"`
+}
+
+type OKXInstrumentResponse struct {
+	InstID   string `json:"instId"`
+	BaseCcy  string `json:"baseCcy"`
+	QuoteCcy string `json:"quoteCcy"`
+	TickSz   string `json:"tickSz"`
+	LotSz    string `json:"lotSz"`
+	MinSz    string `json:"minSz"`
+	MaxLmtSz string `json:"maxLmtSz"`
+	State    string `json:"state"`
+}
+
+type BybitTickerResponse struct {
+	Symbol       string `json:"symbol"`
+	Bid1Price    string `json:"bid1Price"`
+	Ask1Price    string `json:"ask1Price"`
+	LastPrice    string `json:"lastPrice"`
+	PrevPrice24h string `json:"prevPrice24h"`
+	HighPrice24h string `json:"highPrice24h"`
+	LowPrice24h  string `json:"lowPrice24h"`
+	Volume24h    string `json:"volume24h"`
+	Turnover24h  string `json:"turnover24h"`
+}
+
+type BybitTradeResponse struct {
+	ExecID string `json:"execId"`
+	Price  string `json:"price"`
+	Size   string `json:"size"`
+	Side   string `json:"side"`
+	Time   string `json:"time"`
+}
+
+type BybitOrderBookResponse struct {
+	Symbol string     `json:"s"`
+	Bids   [][]string `json:"b"`
+	Asks   [][]string `json:"a"`
+	Ts     int64      `json:"ts"`
+	Update int64      `json:"u"`
+}
+
+type BybitOrderResponse struct {
+	OrderID     string `json:"orderId"`
+	Symbol      string `json:"symbol"`
+	Side        string `json:"side"`
+	OrderType   string `json:"orderType"`
+	OrderStatus string `json:"orderStatus"`
+	Price       string `json:"price"`
+	Qty         string `json:"qty"`
+	CumExecQty  string `json:"cumExecQty"`
+	AvgPrice    string `json:"avgPrice"`
+	CreatedTime string `json:"createdTime"`
+	UpdatedTime string `json:"updatedTime"`
+}
+
+type BybitExecutionResponse struct {
+	ExecID      string `json:"execId"`
+	OrderID     string `json:"orderId"`
+	Symbol      string `json:"symbol"`
+	Side        string `json:"side"`
+	ExecPrice   string `json:"execPrice"`
+	ExecQty     string `json:"execQty"`
+	ExecFee     string `json:"execFee"`
+	FeeCurrency string `json:"feeCurrency"`
+	IsMaker     bool   `json:"isMaker"`
+	ExecTime    string `json:"execTime"`
+}
+
+type BybitInstrumentResponse struct {
+	Symbol        string `json:"symbol"`
+	BaseCoin      string `json:"baseCoin"`
+	QuoteCoin     string `json:"quoteCoin"`
+	Status        string `json:"status"`
+	LotSizeFilter struct {
+		BasePrecision string `json:"basePrecision"`
+		MinOrderQty   string `json:"minOrderQty"`
+		MaxOrderQty   string `json:"maxOrderQty"`
+		MinOrderAmt   string `json:"minOrderAmt"`
+	} `json:"lotSizeFilter"`
+	PriceFilter struct {
+		TickSize string `json:"tickSize"`
+	} `json:"priceFilter"`
+}
+
+type KrakenOrderResponse struct {
+	Status  string  `json:"status"`
+	OpenTm  float64 `json:"opentm"`
+	CloseTm float64 `json:"closetm"`
+	Vol     string  `json:"vol"`
+	VolExec string  `json:"vol_exec"`
+	Cost    string  `json:"cost"`
+	Price   string  `json:"price"`
+	Descr   struct {
+		Pair      string `json:"pair"`
+		Type      string `json:"type"`
+		OrderType string `json:"ordertype"`
+		Price     string `json:"price"`
+	} `json:"descr"`
+}
+
+type KrakenTradeResponse struct {
+	OrderTxID string  `json:"ordertxid"`
+	Pair      string  `json:"pair"`
+	Time      float64 `json:"time"`
+	Type      string  `json:"type"`
+	Price     string  `json:"price"`
+	Vol       string  `json:"vol"`
+	Fee       string  `json:"fee"`
+	Maker     bool    `json:"maker"`
+}
+
+type KrakenAssetPairResponse struct {
+	Altname  string `json:"altname"`
+	Base     string `json:"base"`
+	Quote    string `json:"quote"`
+	LotDec   int32  `json:"lot_decimals"`
+	TickSize string `json:"tick_size"`
+	OrderMin string `json:"ordermin"`
+	CostMin  string `json:"costmin"`
+	Status   string `json:"status"`
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"eyeOne/pkg/logger"
+)
+
+// Symbol is the canonical, exchange-independent notation of a trading pair,
+// written as BASE/QUOTE (e.g. BTC/USDT). Adapters translate it to and from
+// their venue's native format.
+type Symbol struct {
+	Base  string
+	Quote string
+}
+
+// symbolSeparators are accepted on input so that symbols can also be
+// written in URL paths, where "/" is not usable.
+const symbolSeparators = "/-_"
+
+// ParseSymbol parses BTC/USDT, BTC-USDT or BTC_USDT in any letter case.
+func ParseSymbol(value string) (Symbol, error) {
+	log := logger.GetLogger()
+
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if value == "" {
+		err := fmt.Errorf("symbol is required")
+		log.Warn("Validation error", zap.String("field", "symbol"), zap.Error(err))
+		return Symbol{}, err
+	}
+
+	idx := strings.IndexAny(value, symbolSeparators)
+	if idx <= 0 || idx == len(value)-1 || strings.ContainsAny(value[idx+1:], symbolSeparators) {
+		err := fmt.Errorf("symbol must be written as BASE/QUOTE, e.g. BTC/USDT or BTC-USDT (got: %s)", value)
+		log.Warn("Validation error", zap.String("field", "symbol"), zap.Error(err))
+		return Symbol{}, err
+	}
+
+	s := Symbol{Base: value[:idx], Quote: value[idx+1:]}
+	if !validAssetRegex.MatchString(s.Base) || !validAssetRegex.MatchString(s.Quote) {
+		err := fmt.Errorf("symbol assets must be uppercase letters or digits, 2 to 10 characters (got: %s)", value)
+		log.Warn("Validation error", zap.String("field", "symbol"), zap.Error(err))
+		return Symbol{}, err
+	}
+	return s, nil
+}
+
+func (s Symbol) String() string {
+	if s.Quote == "" {
+		return s.Base
+	}
+	return s.Base + "/" + s.Quote
+}
+
+func (s Symbol) IsZero() bool {
+	return s.Base == "" && s.Quote == ""
+}
+
+// Join renders the symbol in a venue format such as BTCUSDT ("") or BTC-USDT ("-").
+func (s Symbol) Join(sep string) string {
+	return s.Base + sep + s.Quote
+}
+
+func (s Symbol) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+func (s *Symbol) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	if value == "" {
+		*s = Symbol{}
+		return nil
+	}
+	parsed, err := ParseSymbol(value)
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
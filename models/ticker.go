@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Ticker is the last price, top of book and rolling 24h statistics of a
+// market. Volume is in the base asset and QuoteVolume in the quote asset;
+// venues that do not publish a field leave it zero. Timestamp is the time
+// of retrieval when the venue does not report one.
+type Ticker struct {
+	Exchange           string          `json:"exchange"`
+	Symbol             Symbol          `json:"symbol"`
+	LastPrice          decimal.Decimal `json:"lastPrice"`
+	BidPrice           decimal.Decimal `json:"bidPrice"`
+	AskPrice           decimal.Decimal `json:"askPrice"`
+	HighPrice          decimal.Decimal `json:"highPrice"`
+	LowPrice           decimal.Decimal `json:"lowPrice"`
+	Volume             decimal.Decimal `json:"volume"`
+	QuoteVolume        decimal.Decimal `json:"quoteVolume"`
+	PriceChange        decimal.Decimal `json:"priceChange"`
+	PriceChangePercent decimal.Decimal `json:"priceChangePercent"`
+	Timestamp          time.Time       `json:"timestamp"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Trade is one print on a market's public tape. Side is the taker's side,
+// "buy" or "sell". TradeID is empty on venues that do not number trades.
+type Trade struct {
+	TradeID   string          `json:"tradeId"`
+	Price     decimal.Decimal `json:"price"`
+	Quantity  decimal.Decimal `json:"quantity"`
+	Side      string          `json:"side"`
+	Timestamp time.Time       `json:"timestamp"`
+}